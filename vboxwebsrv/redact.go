@@ -0,0 +1,118 @@
+package vboxwebsrv
+
+import (
+	"encoding/xml"
+	"reflect"
+)
+
+// Medium property names whose values are secrets
+var secretMediumProperties = map[string]bool{
+	"InitiatorSecret":          true,
+	"InitiatorSecretEncrypted": true,
+	"TargetSecret":             true,
+}
+
+const redactedValue = "[REDACTED]"
+
+// A redacter is a request or response that carries secrets. Call logs the
+// value returned by redacted instead of the message itself.
+type redacter interface {
+	redacted() interface{}
+}
+
+func (request IWebsessionManagerlogon) redacted() interface{} {
+	if request.Password != "" {
+		request.Password = redactedValue
+	}
+
+	return &request
+}
+
+func (request IGuestcreateSession) redacted() interface{} {
+	if request.Password != "" {
+		request.Password = redactedValue
+	}
+
+	return &request
+}
+
+func (request IMediumsetProperty) redacted() interface{} {
+	if secretMediumProperties[request.Name] {
+		request.Value = redactedValue
+	}
+
+	return &request
+}
+
+func (request IMediumsetProperties) redacted() interface{} {
+	request.Values = redactMediumPropertyValues(request.Names, request.Values)
+
+	return &request
+}
+
+func (response IMediumgetPropertyResponse) redacted() interface{} {
+	// The response does not carry the property name, so any value is
+	// treated as secret; IMediumgetProperty requests are still logged.
+	if response.Returnval != "" {
+		response.Returnval = redactedValue
+	}
+
+	return &response
+}
+
+func (response IMediumgetPropertiesResponse) redacted() interface{} {
+	response.Returnval = redactMediumPropertyValues(response.ReturnNames, response.Returnval)
+
+	return &response
+}
+
+func redactMediumPropertyValues(names, values []string) []string {
+	redacted := make([]string, len(values))
+	for i, value := range values {
+		if i < len(names) && secretMediumProperties[names[i]] {
+			value = redactedValue
+		}
+		redacted[i] = value
+	}
+
+	return redacted
+}
+
+// loggableRequest returns the encoded request, with secrets masked if
+// necessary.
+func loggableRequest(request interface{}, encoded string) string {
+	r, ok := request.(redacter)
+	if !ok {
+		return encoded
+	}
+
+	return encodeForLog(r.redacted())
+}
+
+// loggableResponse returns the raw response body, with secrets masked if
+// necessary.
+func loggableResponse(response interface{}, rawbody []byte) string {
+	if _, ok := response.(redacter); !ok {
+		return string(rawbody)
+	}
+
+	decoded := reflect.New(reflect.TypeOf(response).Elem()).Interface()
+	envelope := SOAPEnvelope{Body: SOAPBody{Content: decoded}}
+	if err := xml.Unmarshal(rawbody, &envelope); err != nil || envelope.Body.Fault != nil {
+		return string(rawbody)
+	}
+
+	return encodeForLog(decoded.(redacter).redacted())
+}
+
+func encodeForLog(content interface{}) string {
+	envelope := SOAPEnvelope{}
+	envelope.Body.Content = content
+
+	encoded, err := xml.Marshal(envelope)
+	if err != nil {
+		return redactedValue
+	}
+
+	return string(encoded)
+}
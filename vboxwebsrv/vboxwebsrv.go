@@ -33335,7 +33335,7 @@ func (s *SOAPClient) Call(soapAction string, request, response interface{}) erro
 		err = encoder.Flush()
 	}
 
-	log.Println(buffer.String())
+	log.Println(loggableRequest(request, buffer.String()))
 	if err != nil {
 		return err
 	}
@@ -33373,7 +33373,7 @@ func (s *SOAPClient) Call(soapAction string, request, response interface{}) erro
 		return nil
 	}
 
-	log.Println(string(rawbody))
+	log.Println(loggableResponse(response, rawbody))
 	respEnvelope := new(SOAPEnvelope)
 	respEnvelope.Body = SOAPBody{Content: response}
 	err = xml.Unmarshal(rawbody, respEnvelope)
@@ -0,0 +1,341 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// Names of the groups used by Machine.LimitBandwidth
+const (
+	diskLimitGroupName    = "DiskLimit"
+	networkLimitGroupName = "NetworkLimit"
+)
+
+type BandwidthControl struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+// CreateBandwidthGroup creates a group limiting its members to maxBytesPerSec.
+// The machine must be locked.
+func (bc *BandwidthControl) CreateBandwidthGroup(name string, groupType vboxwebsrv.BandwidthGroupType, maxBytesPerSec int64) (*BandwidthGroup, error) {
+	request := vboxwebsrv.IBandwidthControlcreateBandwidthGroup{This: bc.managedObjectId, Name: name, Type_: &groupType, MaxBytesPerSec: maxBytesPerSec}
+
+	_, err := bc.virtualbox.IBandwidthControlcreateBandwidthGroup(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return bc.GetBandwidthGroup(name)
+}
+
+// DeleteBandwidthGroup deletes the named group, which must have no members.
+func (bc *BandwidthControl) DeleteBandwidthGroup(name string) error {
+	request := vboxwebsrv.IBandwidthControldeleteBandwidthGroup{This: bc.managedObjectId, Name: name}
+
+	_, err := bc.virtualbox.IBandwidthControldeleteBandwidthGroup(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (bc *BandwidthControl) GetAllBandwidthGroups() ([]*BandwidthGroup, error) {
+	request := vboxwebsrv.IBandwidthControlgetAllBandwidthGroups{This: bc.managedObjectId}
+
+	response, err := bc.virtualbox.IBandwidthControlgetAllBandwidthGroups(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	groups := make([]*BandwidthGroup, len(response.Returnval))
+	for i, oid := range response.Returnval {
+		groups[i] = &BandwidthGroup{bc.virtualbox, oid}
+	}
+
+	return groups, nil
+}
+
+func (bc *BandwidthControl) GetBandwidthGroup(name string) (*BandwidthGroup, error) {
+	request := vboxwebsrv.IBandwidthControlgetBandwidthGroup{This: bc.managedObjectId, Name: name}
+
+	response, err := bc.virtualbox.IBandwidthControlgetBandwidthGroup(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &BandwidthGroup{bc.virtualbox, response.Returnval}, nil
+}
+
+func (bc *BandwidthControl) GetNumGroups() (uint32, error) {
+	request := vboxwebsrv.IBandwidthControlgetNumGroups{This: bc.managedObjectId}
+
+	response, err := bc.virtualbox.IBandwidthControlgetNumGroups(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// deleteBandwidthGroupIfExists deletes the named group if there is one.
+func (bc *BandwidthControl) deleteBandwidthGroupIfExists(name string) error {
+	group, err := bc.findBandwidthGroup(name)
+	if err != nil || group == nil {
+		return err
+	}
+
+	return bc.DeleteBandwidthGroup(name)
+}
+
+// findBandwidthGroup returns the named group, or nil if there is none.
+func (bc *BandwidthControl) findBandwidthGroup(name string) (*BandwidthGroup, error) {
+	groups, err := bc.GetAllBandwidthGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		groupName, err := group.GetName()
+		if err != nil {
+			return nil, err
+		}
+
+		if groupName == name {
+			return group, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// setBandwidthGroupLimit sets the limit of the named group, creating it if
+// necessary.
+func (bc *BandwidthControl) setBandwidthGroupLimit(name string, groupType vboxwebsrv.BandwidthGroupType, maxBytesPerSec int64) (*BandwidthGroup, error) {
+	group, err := bc.findBandwidthGroup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if group == nil {
+		return bc.CreateBandwidthGroup(name, groupType, maxBytesPerSec)
+	}
+
+	if err := group.SetMaxBytesPerSec(maxBytesPerSec); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// SetDiskBandwidthGroup moves every attached hard disk into group, or out of
+// any group if group is nil. The machine must be locked for writing.
+func (m *Machine) SetDiskBandwidthGroup(group *BandwidthGroup) error {
+	attachments, err := m.GetMediumAttachments()
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		if attachment.Type_ == nil || *attachment.Type_ != vboxwebsrv.DeviceTypeHardDisk {
+			continue
+		}
+
+		if err := m.SetBandwidthGroupForDevice(attachment.Controller, attachment.Port, attachment.Device, group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetNetworkBandwidthGroup moves every network adapter into group, or out of
+// any group if group is nil. The machine must be locked for writing.
+func (m *Machine) SetNetworkBandwidthGroup(group *BandwidthGroup) error {
+	adapters, err := m.GetNetworkAdapters()
+	if err != nil {
+		return err
+	}
+
+	for _, adapter := range adapters {
+		if err := adapter.SetBandwidthGroup(group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LimitBandwidth caps the combined throughput of the machine's hard disks and
+// of its network adapters, in bytes per second. The groups are named DiskLimit
+// and NetworkLimit and are created as needed. A limit of zero removes the cap,
+// taking the devices out of the group and deleting it. Once the devices are
+// in the groups, later calls only change the limits, which works while the
+// machine is running; creating or deleting the groups and moving devices in or
+// out of them needs a machine that is powered off.
+func (m *Machine) LimitBandwidth(diskBytesPerSec, networkBytesPerSec int64) error {
+	updated := false
+	err := m.WithLock(vboxwebsrv.LockTypeShared, func(mutable *Machine) error {
+		var err error
+		updated, err = mutable.updateBandwidthLimits(diskBytesPerSec, networkBytesPerSec)
+		return err
+	})
+	if err != nil || updated {
+		return err
+	}
+
+	return m.WithLock(vboxwebsrv.LockTypeWrite, func(mutable *Machine) error {
+		bc, err := mutable.GetBandwidthControl()
+		if err != nil {
+			return err
+		}
+
+		var diskGroup, networkGroup *BandwidthGroup
+		if diskBytesPerSec > 0 {
+			if diskGroup, err = bc.setBandwidthGroupLimit(diskLimitGroupName, vboxwebsrv.BandwidthGroupTypeDisk, diskBytesPerSec); err != nil {
+				return err
+			}
+		}
+
+		if networkBytesPerSec > 0 {
+			if networkGroup, err = bc.setBandwidthGroupLimit(networkLimitGroupName, vboxwebsrv.BandwidthGroupTypeNetwork, networkBytesPerSec); err != nil {
+				return err
+			}
+		}
+
+		if err := mutable.SetDiskBandwidthGroup(diskGroup); err != nil {
+			return err
+		}
+
+		if err := mutable.SetNetworkBandwidthGroup(networkGroup); err != nil {
+			return err
+		}
+
+		if diskGroup == nil {
+			if err := bc.deleteBandwidthGroupIfExists(diskLimitGroupName); err != nil {
+				return err
+			}
+		}
+
+		if networkGroup == nil {
+			return bc.deleteBandwidthGroupIfExists(networkLimitGroupName)
+		}
+
+		return nil
+	})
+}
+
+// updateBandwidthLimits changes the limits of the groups used by
+// LimitBandwidth if the devices are already in the right groups. It reports
+// false, changing nothing, if groups need to be created, assigned or deleted.
+func (m *Machine) updateBandwidthLimits(diskBytesPerSec, networkBytesPerSec int64) (bool, error) {
+	bc, err := m.GetBandwidthControl()
+	if err != nil {
+		return false, err
+	}
+
+	diskGroupNames, err := m.diskBandwidthGroupNames()
+	if err != nil {
+		return false, err
+	}
+
+	networkGroupNames, err := m.networkBandwidthGroupNames()
+	if err != nil {
+		return false, err
+	}
+
+	limits := []struct {
+		groupName      string
+		deviceGroups   []string
+		maxBytesPerSec int64
+	}{
+		{diskLimitGroupName, diskGroupNames, diskBytesPerSec},
+		{networkLimitGroupName, networkGroupNames, networkBytesPerSec},
+	}
+
+	groups := make([]*BandwidthGroup, len(limits))
+	for i, limit := range limits {
+		want := ""
+		if limit.maxBytesPerSec > 0 {
+			want = limit.groupName
+		}
+
+		for _, name := range limit.deviceGroups {
+			if name != want {
+				return false, nil
+			}
+		}
+
+		if groups[i], err = bc.findBandwidthGroup(limit.groupName); err != nil {
+			return false, err
+		}
+		if (groups[i] != nil) != (limit.maxBytesPerSec > 0) {
+			return false, nil
+		}
+	}
+
+	for i, limit := range limits {
+		if groups[i] == nil {
+			continue
+		}
+
+		if err := groups[i].SetMaxBytesPerSec(limit.maxBytesPerSec); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// diskBandwidthGroupNames returns the bandwidth group of each hard disk, or ""
+// for disks in no group.
+func (m *Machine) diskBandwidthGroupNames() ([]string, error) {
+	attachments, err := m.GetMediumAttachments()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, attachment := range attachments {
+		if attachment.Type_ == nil || *attachment.Type_ != vboxwebsrv.DeviceTypeHardDisk {
+			continue
+		}
+
+		name := ""
+		if attachment.BandwidthGroup != "" {
+			group := &BandwidthGroup{m.virtualbox, attachment.BandwidthGroup}
+			if name, err = group.GetName(); err != nil {
+				return nil, err
+			}
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// networkBandwidthGroupNames returns the bandwidth group of each network
+// adapter, or "" for adapters in no group.
+func (m *Machine) networkBandwidthGroupNames() ([]string, error) {
+	adapters, err := m.GetNetworkAdapters()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(adapters))
+	for i, adapter := range adapters {
+		group, err := adapter.GetBandwidthGroup()
+		if err != nil {
+			return nil, err
+		}
+
+		if group != nil {
+			if names[i], err = group.GetName(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,66 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type BandwidthGroup struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (bg *BandwidthGroup) GetMaxBytesPerSec() (int64, error) {
+	request := vboxwebsrv.IBandwidthGroupgetMaxBytesPerSec{This: bg.managedObjectId}
+
+	response, err := bg.virtualbox.IBandwidthGroupgetMaxBytesPerSec(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (bg *BandwidthGroup) GetName() (string, error) {
+	request := vboxwebsrv.IBandwidthGroupgetName{This: bg.managedObjectId}
+
+	response, err := bg.virtualbox.IBandwidthGroupgetName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetReference returns the number of devices and adapters in the group.
+func (bg *BandwidthGroup) GetReference() (uint32, error) {
+	request := vboxwebsrv.IBandwidthGroupgetReference{This: bg.managedObjectId}
+
+	response, err := bg.virtualbox.IBandwidthGroupgetReference(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (bg *BandwidthGroup) GetType() (*vboxwebsrv.BandwidthGroupType, error) {
+	request := vboxwebsrv.IBandwidthGroupgetType{This: bg.managedObjectId}
+
+	response, err := bg.virtualbox.IBandwidthGroupgetType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (bg *BandwidthGroup) SetMaxBytesPerSec(maxBytesPerSec int64) error {
+	request := vboxwebsrv.IBandwidthGroupsetMaxBytesPerSec{This: bg.managedObjectId, MaxBytesPerSec: maxBytesPerSec}
+
+	_, err := bg.virtualbox.IBandwidthGroupsetMaxBytesPerSec(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
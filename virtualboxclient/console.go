@@ -0,0 +1,21 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type Console struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (c *Console) GetGuest() (*Guest, error) {
+	request := vboxwebsrv.IConsolegetGuest{This: c.managedObjectId}
+
+	response, err := c.virtualbox.IConsolegetGuest(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Guest{virtualbox: c.virtualbox, managedObjectId: response.Returnval}, nil
+}
@@ -0,0 +1,373 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// DHCPOptions maps DHCP options to their values.
+type DHCPOptions map[vboxwebsrv.DhcpOpt]string
+
+// DHCPVmSlot identifies a network adapter of a machine with slot options.
+type DHCPVmSlot struct {
+	VmName string
+	Slot   int32
+}
+
+// DHCP option codes, used in the string form of options returned by
+// IDHCPServer
+var dhcpOptions = map[int]vboxwebsrv.DhcpOpt{
+	1:   vboxwebsrv.DhcpOptSubnetMask,
+	2:   vboxwebsrv.DhcpOptTimeOffset,
+	3:   vboxwebsrv.DhcpOptRouter,
+	4:   vboxwebsrv.DhcpOptTimeServer,
+	5:   vboxwebsrv.DhcpOptNameServer,
+	6:   vboxwebsrv.DhcpOptDomainNameServer,
+	7:   vboxwebsrv.DhcpOptLogServer,
+	8:   vboxwebsrv.DhcpOptCookie,
+	9:   vboxwebsrv.DhcpOptLPRServer,
+	10:  vboxwebsrv.DhcpOptImpressServer,
+	11:  vboxwebsrv.DhcpOptResourseLocationServer,
+	12:  vboxwebsrv.DhcpOptHostName,
+	13:  vboxwebsrv.DhcpOptBootFileSize,
+	14:  vboxwebsrv.DhcpOptMeritDumpFile,
+	15:  vboxwebsrv.DhcpOptDomainName,
+	16:  vboxwebsrv.DhcpOptSwapServer,
+	17:  vboxwebsrv.DhcpOptRootPath,
+	18:  vboxwebsrv.DhcpOptExtensionPath,
+	19:  vboxwebsrv.DhcpOptIPForwardingEnableDisable,
+	20:  vboxwebsrv.DhcpOptNonLocalSourceRoutingEnableDisable,
+	21:  vboxwebsrv.DhcpOptPolicyFilter,
+	22:  vboxwebsrv.DhcpOptMaximumDatagramReassemblySize,
+	23:  vboxwebsrv.DhcpOptDefaultIPTime2Live,
+	24:  vboxwebsrv.DhcpOptPathMTUAgingTimeout,
+	25:  vboxwebsrv.DhcpOptIPLayerParametersPerInterface,
+	26:  vboxwebsrv.DhcpOptInterfaceMTU,
+	27:  vboxwebsrv.DhcpOptAllSubnetsAreLocal,
+	28:  vboxwebsrv.DhcpOptBroadcastAddress,
+	29:  vboxwebsrv.DhcpOptPerformMaskDiscovery,
+	30:  vboxwebsrv.DhcpOptMaskSupplier,
+	31:  vboxwebsrv.DhcpOptPerformRouteDiscovery,
+	32:  vboxwebsrv.DhcpOptRouterSolicitationAddress,
+	33:  vboxwebsrv.DhcpOptStaticRoute,
+	34:  vboxwebsrv.DhcpOptTrailerEncapsulation,
+	35:  vboxwebsrv.DhcpOptARPCacheTimeout,
+	36:  vboxwebsrv.DhcpOptEthernetEncapsulation,
+	37:  vboxwebsrv.DhcpOptTCPDefaultTTL,
+	38:  vboxwebsrv.DhcpOptTCPKeepAliveInterval,
+	39:  vboxwebsrv.DhcpOptTCPKeepAliveGarbage,
+	40:  vboxwebsrv.DhcpOptNetworkInformationServiceDomain,
+	41:  vboxwebsrv.DhcpOptNetworkInformationServiceServers,
+	42:  vboxwebsrv.DhcpOptNetworkTimeProtocolServers,
+	43:  vboxwebsrv.DhcpOptVendorSpecificInformation,
+	44:  vboxwebsrv.DhcpOptOption44,
+	45:  vboxwebsrv.DhcpOptOption45,
+	46:  vboxwebsrv.DhcpOptOption46,
+	47:  vboxwebsrv.DhcpOptOption47,
+	48:  vboxwebsrv.DhcpOptOption48,
+	49:  vboxwebsrv.DhcpOptOption49,
+	51:  vboxwebsrv.DhcpOptIPAddressLeaseTime,
+	64:  vboxwebsrv.DhcpOptOption64,
+	65:  vboxwebsrv.DhcpOptOption65,
+	66:  vboxwebsrv.DhcpOptTFTPServerName,
+	67:  vboxwebsrv.DhcpOptBootfileName,
+	68:  vboxwebsrv.DhcpOptOption68,
+	69:  vboxwebsrv.DhcpOptOption69,
+	70:  vboxwebsrv.DhcpOptOption70,
+	71:  vboxwebsrv.DhcpOptOption71,
+	72:  vboxwebsrv.DhcpOptOption72,
+	73:  vboxwebsrv.DhcpOptOption73,
+	74:  vboxwebsrv.DhcpOptOption74,
+	75:  vboxwebsrv.DhcpOptOption75,
+	119: vboxwebsrv.DhcpOptOption119,
+}
+
+// Lease time meaning infinity, in seconds
+const dhcpInfiniteLeaseTime = "4294967295"
+
+type DHCPServer struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (ds *DHCPServer) AddGlobalOption(option vboxwebsrv.DhcpOpt, value string) error {
+	request := vboxwebsrv.IDHCPServeraddGlobalOption{This: ds.managedObjectId, Option: &option, Value: value}
+
+	_, err := ds.virtualbox.IDHCPServeraddGlobalOption(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// AddVmSlotOption sets an option for the network adapter in the given slot of
+// the named machine only.
+func (ds *DHCPServer) AddVmSlotOption(vmName string, slot int32, option vboxwebsrv.DhcpOpt, value string) error {
+	request := vboxwebsrv.IDHCPServeraddVmSlotOption{This: ds.managedObjectId, Vmname: vmName, Slot: slot, Option: &option, Value: value}
+
+	_, err := ds.virtualbox.IDHCPServeraddVmSlotOption(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ds *DHCPServer) GetEnabled() (bool, error) {
+	request := vboxwebsrv.IDHCPServergetEnabled{This: ds.managedObjectId}
+
+	response, err := ds.virtualbox.IDHCPServergetEnabled(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ds *DHCPServer) GetGlobalOptions() (DHCPOptions, error) {
+	request := vboxwebsrv.IDHCPServergetGlobalOptions{This: ds.managedObjectId}
+
+	response, err := ds.virtualbox.IDHCPServergetGlobalOptions(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return parseDHCPOptions(response.Returnval)
+}
+
+func (ds *DHCPServer) GetIPAddress() (string, error) {
+	request := vboxwebsrv.IDHCPServergetIPAddress{This: ds.managedObjectId}
+
+	response, err := ds.virtualbox.IDHCPServergetIPAddress(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ds *DHCPServer) GetLowerIP() (string, error) {
+	request := vboxwebsrv.IDHCPServergetLowerIP{This: ds.managedObjectId}
+
+	response, err := ds.virtualbox.IDHCPServergetLowerIP(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetMacOptions returns the options the server hands to the adapter with the
+// given MAC address, combining global and per-slot options.
+func (ds *DHCPServer) GetMacOptions(mac string) (DHCPOptions, error) {
+	request := vboxwebsrv.IDHCPServergetMacOptions{This: ds.managedObjectId, Mac: mac}
+
+	response, err := ds.virtualbox.IDHCPServergetMacOptions(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return parseDHCPOptions(response.Returnval)
+}
+
+func (ds *DHCPServer) GetNetworkMask() (string, error) {
+	request := vboxwebsrv.IDHCPServergetNetworkMask{This: ds.managedObjectId}
+
+	response, err := ds.virtualbox.IDHCPServergetNetworkMask(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ds *DHCPServer) GetNetworkName() (string, error) {
+	request := vboxwebsrv.IDHCPServergetNetworkName{This: ds.managedObjectId}
+
+	response, err := ds.virtualbox.IDHCPServergetNetworkName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ds *DHCPServer) GetUpperIP() (string, error) {
+	request := vboxwebsrv.IDHCPServergetUpperIP{This: ds.managedObjectId}
+
+	response, err := ds.virtualbox.IDHCPServergetUpperIP(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetVmConfigs returns the machine adapters that have slot options.
+func (ds *DHCPServer) GetVmConfigs() ([]DHCPVmSlot, error) {
+	request := vboxwebsrv.IDHCPServergetVmConfigs{This: ds.managedObjectId}
+
+	response, err := ds.virtualbox.IDHCPServergetVmConfigs(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	slots := make([]DHCPVmSlot, len(response.Returnval))
+	for i, config := range response.Returnval {
+		if slots[i], err = parseDHCPVmSlot(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return slots, nil
+}
+
+func (ds *DHCPServer) GetVmSlotOptions(vmName string, slot int32) (DHCPOptions, error) {
+	request := vboxwebsrv.IDHCPServergetVmSlotOptions{This: ds.managedObjectId, Vmname: vmName, Slot: slot}
+
+	response, err := ds.virtualbox.IDHCPServergetVmSlotOptions(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return parseDHCPOptions(response.Returnval)
+}
+
+func (ds *DHCPServer) RemoveVmSlotOptions(vmName string, slot int32) error {
+	request := vboxwebsrv.IDHCPServerremoveVmSlotOptions{This: ds.managedObjectId, Vmname: vmName, Slot: slot}
+
+	_, err := ds.virtualbox.IDHCPServerremoveVmSlotOptions(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// SetConfiguration sets the server's own address and netmask and the range of
+// addresses it hands out.
+func (ds *DHCPServer) SetConfiguration(ipAddress, networkMask, fromIPAddress, toIPAddress string) error {
+	request := vboxwebsrv.IDHCPServersetConfiguration{
+		This:          ds.managedObjectId,
+		IPAddress:     ipAddress,
+		NetworkMask:   networkMask,
+		FromIPAddress: fromIPAddress,
+		ToIPAddress:   toIPAddress,
+	}
+
+	_, err := ds.virtualbox.IDHCPServersetConfiguration(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ds *DHCPServer) SetEnabled(enabled bool) error {
+	request := vboxwebsrv.IDHCPServersetEnabled{This: ds.managedObjectId, Enabled: enabled}
+
+	_, err := ds.virtualbox.IDHCPServersetEnabled(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// Start starts the DHCP server process for the network. For host-only
+// networks VBoxManage uses trunkName "" and trunkType "netflt".
+func (ds *DHCPServer) Start(networkName, trunkName, trunkType string) error {
+	request := vboxwebsrv.IDHCPServerstart{This: ds.managedObjectId, NetworkName: networkName, TrunkName: trunkName, TrunkType: trunkType}
+
+	_, err := ds.virtualbox.IDHCPServerstart(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ds *DHCPServer) Stop() error {
+	request := vboxwebsrv.IDHCPServerstop{This: ds.managedObjectId}
+
+	_, err := ds.virtualbox.IDHCPServerstop(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// GetAdapterOptions returns the options the server hands to the network
+// adapter in the given slot of machine, looked up by the adapter's MAC
+// address.
+func (ds *DHCPServer) GetAdapterOptions(machine *Machine, slot uint32) (DHCPOptions, error) {
+	adapter, err := machine.GetNetworkAdapter(slot)
+	if err != nil {
+		return nil, err
+	}
+
+	mac, err := adapter.GetMACAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.GetMacOptions(mac)
+}
+
+// PinAdapterLease makes the lease the server hands to the network adapter in
+// the given slot of machine never expire, so the adapter's MAC address keeps
+// the first address it is given. The 4.3 API cannot assign a chosen address;
+// restrict the range with SetConfiguration to control which one it gets.
+func (ds *DHCPServer) PinAdapterLease(machine *Machine, slot uint32) error {
+	name, err := machine.GetName()
+	if err != nil {
+		return err
+	}
+
+	return ds.AddVmSlotOption(name, int32(slot), vboxwebsrv.DhcpOptIPAddressLeaseTime, dhcpInfiniteLeaseTime)
+}
+
+// parseDHCPOptions parses options in the form "code:value".
+func parseDHCPOptions(options []string) (DHCPOptions, error) {
+	parsed := make(DHCPOptions, len(options))
+	for _, option := range options {
+		fields := strings.SplitN(option, ":", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed DHCP option %q", option)
+		}
+
+		code, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("malformed DHCP option %q", option)
+		}
+
+		opt, ok := dhcpOptions[code]
+		if !ok {
+			return nil, fmt.Errorf("unknown DHCP option %q", option)
+		}
+
+		parsed[opt] = fields[1]
+	}
+
+	return parsed, nil
+}
+
+// parseDHCPVmSlot parses a VM config in the form "[vmName]:slot".
+func parseDHCPVmSlot(config string) (DHCPVmSlot, error) {
+	end := strings.LastIndex(config, "]:")
+	if !strings.HasPrefix(config, "[") || end < 0 {
+		return DHCPVmSlot{}, fmt.Errorf("malformed DHCP VM config %q", config)
+	}
+
+	slot, err := strconv.ParseInt(config[end+2:], 10, 32)
+	if err != nil {
+		return DHCPVmSlot{}, fmt.Errorf("malformed DHCP VM config %q", config)
+	}
+
+	return DHCPVmSlot{VmName: config[1:end], Slot: int32(slot)}, nil
+}
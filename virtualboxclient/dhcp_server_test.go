@@ -0,0 +1,86 @@
+package virtualboxclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+func TestParseDHCPOptions(t *testing.T) {
+	tests := []struct {
+		options []string
+		want    DHCPOptions
+		wantErr bool
+	}{
+		{options: nil, want: DHCPOptions{}},
+		{
+			options: []string{"1:255.255.255.0", "3:192.168.56.1"},
+			want:    DHCPOptions{vboxwebsrv.DhcpOptSubnetMask: "255.255.255.0", vboxwebsrv.DhcpOptRouter: "192.168.56.1"},
+		},
+		{
+			options: []string{"15:example:test", "51:3600", "119:"},
+			want:    DHCPOptions{vboxwebsrv.DhcpOptDomainName: "example:test", vboxwebsrv.DhcpOptIPAddressLeaseTime: "3600", vboxwebsrv.DhcpOptOption119: ""},
+		},
+		{
+			options: []string{"6:fd00::1"},
+			want:    DHCPOptions{vboxwebsrv.DhcpOptDomainNameServer: "fd00::1"},
+		},
+		{options: []string{"1"}, wantErr: true},
+		{options: []string{":255.255.255.0"}, wantErr: true},
+		{options: []string{"mask:255.255.255.0"}, wantErr: true},
+		{options: []string{"50:192.168.56.10"}, wantErr: true},
+		{options: []string{"1:255.255.255.0", "bad"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDHCPOptions(tt.options)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDHCPOptions(%q) = %v, want error", tt.options, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseDHCPOptions(%q) returned error: %v", tt.options, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseDHCPOptions(%q) = %v, want %v", tt.options, got, tt.want)
+		}
+	}
+}
+
+func TestParseDHCPVmSlot(t *testing.T) {
+	tests := []struct {
+		config  string
+		want    DHCPVmSlot
+		wantErr bool
+	}{
+		{config: "[web]:0", want: DHCPVmSlot{VmName: "web", Slot: 0}},
+		{config: "[build agent 2]:7", want: DHCPVmSlot{VmName: "build agent 2", Slot: 7}},
+		{config: "[odd]:name]:3", want: DHCPVmSlot{VmName: "odd]:name", Slot: 3}},
+		{config: "[]:1", want: DHCPVmSlot{VmName: "", Slot: 1}},
+		{config: "", wantErr: true},
+		{config: "web:0", wantErr: true},
+		{config: "[web]0", wantErr: true},
+		{config: "[web]:", wantErr: true},
+		{config: "[web]:x", wantErr: true},
+		{config: "[web]:4294967296", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDHCPVmSlot(tt.config)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDHCPVmSlot(%q) = %+v, want error", tt.config, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseDHCPVmSlot(%q) returned error: %v", tt.config, err)
+		} else if got != tt.want {
+			t.Errorf("parseDHCPVmSlot(%q) = %+v, want %+v", tt.config, got, tt.want)
+		}
+	}
+}
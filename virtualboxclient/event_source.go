@@ -0,0 +1,97 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type EventSource struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+// EventListener is a passive listener, from which events are fetched with
+// EventSource.GetEvent.
+type EventListener struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+type Event struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (es *EventSource) CreateListener() (*EventListener, error) {
+	request := vboxwebsrv.IEventSourcecreateListener{This: es.managedObjectId}
+
+	response, err := es.virtualbox.IEventSourcecreateListener(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &EventListener{es.virtualbox, response.Returnval}, nil
+}
+
+// EventProcessed must be called for each event returned by GetEvent.
+func (es *EventSource) EventProcessed(listener *EventListener, event *Event) error {
+	request := vboxwebsrv.IEventSourceeventProcessed{This: es.managedObjectId, Listener: listener.managedObjectId, Event: event.managedObjectId}
+
+	_, err := es.virtualbox.IEventSourceeventProcessed(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// GetEvent waits up to timeout milliseconds for the next event for listener.
+// It returns nil if there is none.
+func (es *EventSource) GetEvent(listener *EventListener, timeout int32) (*Event, error) {
+	request := vboxwebsrv.IEventSourcegetEvent{This: es.managedObjectId, Listener: listener.managedObjectId, Timeout: timeout}
+
+	response, err := es.virtualbox.IEventSourcegetEvent(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	if response.Returnval == "" {
+		return nil, nil
+	}
+
+	return &Event{es.virtualbox, response.Returnval}, nil
+}
+
+// RegisterListener subscribes listener to the given event types. Listeners
+// created with CreateListener must be registered as passive.
+func (es *EventSource) RegisterListener(listener *EventListener, interesting []*vboxwebsrv.VBoxEventType, active bool) error {
+	request := vboxwebsrv.IEventSourceregisterListener{This: es.managedObjectId, Listener: listener.managedObjectId, Interesting: interesting, Active: active}
+
+	_, err := es.virtualbox.IEventSourceregisterListener(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (es *EventSource) UnregisterListener(listener *EventListener) error {
+	request := vboxwebsrv.IEventSourceunregisterListener{This: es.managedObjectId, Listener: listener.managedObjectId}
+
+	_, err := es.virtualbox.IEventSourceunregisterListener(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (e *Event) GetType() (*vboxwebsrv.VBoxEventType, error) {
+	request := vboxwebsrv.IEventgetType{This: e.managedObjectId}
+
+	response, err := e.virtualbox.IEventgetType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
@@ -0,0 +1,70 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// FsObjInfo describes a file system object in a guest.
+type FsObjInfo struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+// GetFileAttributes returns the attributes as reported by the guest, usually
+// in "ls -l" notation such as "drwxr-xr-x".
+func (fi *FsObjInfo) GetFileAttributes() (string, error) {
+	request := vboxwebsrv.IFsObjInfogetFileAttributes{This: fi.managedObjectId}
+
+	response, err := fi.virtualbox.IFsObjInfogetFileAttributes(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetModificationTime returns the time of the last change of the object's
+// data in nanoseconds since the Unix epoch.
+func (fi *FsObjInfo) GetModificationTime() (int64, error) {
+	request := vboxwebsrv.IFsObjInfogetModificationTime{This: fi.managedObjectId}
+
+	response, err := fi.virtualbox.IFsObjInfogetModificationTime(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (fi *FsObjInfo) GetName() (string, error) {
+	request := vboxwebsrv.IFsObjInfogetName{This: fi.managedObjectId}
+
+	response, err := fi.virtualbox.IFsObjInfogetName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (fi *FsObjInfo) GetObjectSize() (int64, error) {
+	request := vboxwebsrv.IFsObjInfogetObjectSize{This: fi.managedObjectId}
+
+	response, err := fi.virtualbox.IFsObjInfogetObjectSize(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (fi *FsObjInfo) GetType() (*vboxwebsrv.FsObjType, error) {
+	request := vboxwebsrv.IFsObjInfogetType{This: fi.managedObjectId}
+
+	response, err := fi.virtualbox.IFsObjInfogetType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
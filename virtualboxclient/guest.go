@@ -0,0 +1,279 @@
+package virtualboxclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+var (
+	// ErrGuestAuthentication means the guest refused to log on the user.
+	ErrGuestAuthentication = errors.New("guest refused the credentials")
+
+	// ErrGuestAdditionsNotRunning means the Guest Additions service needed for
+	// guest control is not installed or not running yet.
+	ErrGuestAdditionsNotRunning = errors.New("guest additions are not running")
+
+	// ErrGuestTimeout means a guest session or process did not finish in time,
+	// either because the context's deadline passed or the guest killed it.
+	ErrGuestTimeout = errors.New("timed out waiting for the guest")
+)
+
+// Name of the guest sessions opened by this package
+const guestSessionName = "virtualboxclient"
+
+// Longest single wait on the server, so that cancelled contexts are noticed
+const guestPollIntervalMS = 1000
+
+// Guest is the guest operating system of a running machine.
+type Guest struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+
+	// Holds the shared lock taken by Machine.GetGuest, in a web session of
+	// its own; nil for a guest from Console.GetGuest
+	session *Session
+}
+
+// CreateSession starts creating a guest session as the given user. The session
+// is usable once it reaches GuestSessionStatusStarted; OpenSession waits for
+// that.
+func (g *Guest) CreateSession(user, password, domain, sessionName string) (*GuestSession, error) {
+	request := vboxwebsrv.IGuestcreateSession{This: g.managedObjectId, User: user, Password: password, Domain: domain, SessionName: sessionName}
+
+	response, err := g.virtualbox.IGuestcreateSession(&request)
+	if err != nil {
+		return nil, classifyGuestError(err)
+	}
+
+	return &GuestSession{g.virtualbox, response.Returnval}, nil
+}
+
+// Exec runs command in the guest as user and waits for it to exit. A non-zero
+// exit code is not an error; check the result.
+func (g *Guest) Exec(ctx context.Context, user, password, command string, args []string, env []string) (result *GuestProcessResult, err error) {
+	gs, err := g.OpenSession(ctx, user, password)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := gs.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	process, err := gs.ProcessCreate(command, args, env, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return process.Wait(ctx)
+}
+
+func (g *Guest) GetAdditionsRevision() (uint32, error) {
+	request := vboxwebsrv.IGuestgetAdditionsRevision{This: g.managedObjectId}
+
+	response, err := g.virtualbox.IGuestgetAdditionsRevision(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetAdditionsRunLevel returns how far the Guest Additions have started. Guest
+// control needs at least AdditionsRunLevelTypeUserland.
+func (g *Guest) GetAdditionsRunLevel() (*vboxwebsrv.AdditionsRunLevelType, error) {
+	request := vboxwebsrv.IGuestgetAdditionsRunLevel{This: g.managedObjectId}
+
+	response, err := g.virtualbox.IGuestgetAdditionsRunLevel(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetAdditionsStatus reports whether the Guest Additions have reached at least
+// the given run level.
+func (g *Guest) GetAdditionsStatus(level vboxwebsrv.AdditionsRunLevelType) (bool, error) {
+	request := vboxwebsrv.IGuestgetAdditionsStatus{This: g.managedObjectId, Level: &level}
+
+	response, err := g.virtualbox.IGuestgetAdditionsStatus(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (g *Guest) GetAdditionsVersion() (string, error) {
+	request := vboxwebsrv.IGuestgetAdditionsVersion{This: g.managedObjectId}
+
+	response, err := g.virtualbox.IGuestgetAdditionsVersion(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetFacilities returns the Guest Additions facilities the guest has reported.
+// LastUpdated is in milliseconds since the epoch.
+func (g *Guest) GetFacilities() ([]*vboxwebsrv.IAdditionsFacility, error) {
+	request := vboxwebsrv.IGuestgetFacilities{This: g.managedObjectId}
+
+	response, err := g.virtualbox.IGuestgetFacilities(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetFacilityStatus returns the status of a Guest Additions facility and when
+// it last changed, in milliseconds since the epoch.
+func (g *Guest) GetFacilityStatus(facility vboxwebsrv.AdditionsFacilityType) (*vboxwebsrv.AdditionsFacilityStatus, int64, error) {
+	request := vboxwebsrv.IGuestgetFacilityStatus{This: g.managedObjectId, Facility: &facility}
+
+	response, err := g.virtualbox.IGuestgetFacilityStatus(&request)
+	if err != nil {
+		return nil, 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, response.Timestamp, nil
+}
+
+// GetOSTypeId returns the OS type reported by the Guest Additions, or the
+// machine's configured OS type if they are not running.
+func (g *Guest) GetOSTypeId() (string, error) {
+	request := vboxwebsrv.IGuestgetOSTypeId{This: g.managedObjectId}
+
+	response, err := g.virtualbox.IGuestgetOSTypeId(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// OpenSession creates a guest session as user and waits until it has started.
+func (g *Guest) OpenSession(ctx context.Context, user, password string) (*GuestSession, error) {
+	runLevel, err := g.GetAdditionsRunLevel()
+	if err != nil {
+		return nil, err
+	}
+
+	if runLevel == nil || *runLevel == vboxwebsrv.AdditionsRunLevelTypeNone || *runLevel == vboxwebsrv.AdditionsRunLevelTypeSystem {
+		return nil, ErrGuestAdditionsNotRunning
+	}
+
+	gs, err := g.CreateSession(user, password, "", guestSessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := gs.waitForStart(ctx); err != nil {
+		gs.Close()
+		return nil, err
+	}
+
+	return gs, nil
+}
+
+// Release unlocks the machine locked by Machine.GetGuest and logs off the web
+// session it was locked through. The guest and its sessions must not be used
+// afterwards. It does nothing for a guest from Console.GetGuest, whose session
+// belongs to the caller.
+func (g *Guest) Release() error {
+	if g.session == nil {
+		return nil
+	}
+
+	err := g.session.UnlockMachine()
+	if logoffErr := g.virtualbox.Logoff(); err == nil {
+		err = logoffErr
+	}
+
+	return err
+}
+
+// UpdateAdditions installs the Guest Additions from an ISO image on the
+// VirtualBox server's host, passing args to the installer, and waits for the
+// update to finish. If the context is done first the update is cancelled. With
+// AdditionsUpdateFlagWaitForUpdateStartOnly it only waits for the installer to
+// start. The returned Progress can be inspected after an error.
+func (g *Guest) UpdateAdditions(ctx context.Context, isoPath string, args []string, flags []*vboxwebsrv.AdditionsUpdateFlag) (*Progress, error) {
+	running, err := g.GetAdditionsStatus(vboxwebsrv.AdditionsRunLevelTypeUserland)
+	if err != nil {
+		return nil, err
+	}
+	if !running {
+		return nil, ErrGuestAdditionsNotRunning
+	}
+
+	progress, err := g.UpdateGuestAdditions(isoPath, args, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	return progress, progress.Wait(ctx)
+}
+
+// UpdateGuestAdditions starts installing the Guest Additions from an ISO image
+// on the VirtualBox server's host.
+func (g *Guest) UpdateGuestAdditions(source string, arguments []string, flags []*vboxwebsrv.AdditionsUpdateFlag) (*Progress, error) {
+	request := vboxwebsrv.IGuestupdateGuestAdditions{This: g.managedObjectId, Source: source, Arguments: arguments, Flags: flags}
+
+	response, err := g.virtualbox.IGuestupdateGuestAdditions(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{g.virtualbox, response.Returnval}, nil
+}
+
+// classifyGuestError turns the errors VirtualBox reports for refused logons
+// into ErrGuestAuthentication.
+func classifyGuestError(err error) error {
+	message := strings.ToLower(err.Error())
+	for _, s := range []string{"verr_authentication_failure", "verr_account_restricted", "not able to logon", "logon failure"} {
+		if strings.Contains(message, s) {
+			return fmt.Errorf("%w: %v", ErrGuestAuthentication, err)
+		}
+	}
+
+	return err
+}
+
+// contextError returns ErrGuestTimeout if the context's deadline passed and
+// the context's error otherwise.
+func contextError(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrGuestTimeout
+	}
+
+	return ctx.Err()
+}
+
+// waitTimeoutMS returns how long a single wait on the server may take without
+// overrunning the context's deadline.
+func waitTimeoutMS(ctx context.Context) uint32 {
+	timeout := uint32(guestPollIntervalMS)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline) / time.Millisecond
+		if remaining < 1 {
+			remaining = 1
+		}
+		if remaining < time.Duration(timeout) {
+			timeout = uint32(remaining)
+		}
+	}
+
+	return timeout
+}
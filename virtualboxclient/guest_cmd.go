@@ -0,0 +1,313 @@
+package virtualboxclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// Handles of a guest process's standard streams
+const (
+	guestStdin  = 0
+	guestStdout = 1
+	guestStderr = 2
+)
+
+// Largest chunk sent or requested in a single call, keeping SOAP messages
+// reasonably small
+const guestStreamChunkSize = 64 * 1024
+
+// GuestCmd is a command to run in a guest session, modelled on os/exec.Cmd.
+type GuestCmd struct {
+	Path string
+	Args []string
+
+	// "NAME=value" entries added to the session's environment
+	Env []string
+
+	// If Stdin is nil the process reads from an empty input
+	Stdin io.Reader
+
+	// If Stdout or Stderr is nil the output is discarded by the guest
+	Stdout io.Writer
+	Stderr io.Writer
+
+	session *GuestSession
+	ctx     context.Context
+	process *GuestProcess
+
+	stdinPipe  *guestProcessWriter
+	stdoutPipe *guestProcessReader
+	stderrPipe *guestProcessReader
+
+	copying sync.WaitGroup
+	copyMu  sync.Mutex
+	copyErr error
+}
+
+// Command returns a command to run path with the given arguments in the
+// session.
+func (gs *GuestSession) Command(path string, args ...string) *GuestCmd {
+	return &GuestCmd{Path: path, Args: args, session: gs}
+}
+
+// StdinPipe returns a writer connected to the process's standard input.
+// Closing it sends end-of-file. It must be called before Start.
+func (c *GuestCmd) StdinPipe() (io.WriteCloser, error) {
+	if c.Stdin != nil || c.stdinPipe != nil {
+		return nil, errors.New("stdin already set")
+	}
+	if c.process != nil {
+		return nil, errors.New("StdinPipe after process started")
+	}
+
+	c.stdinPipe = &guestProcessWriter{}
+	return c.stdinPipe, nil
+}
+
+// StdoutPipe returns a reader connected to the process's standard output. All
+// output must be read before calling Wait. It must be called before Start.
+func (c *GuestCmd) StdoutPipe() (io.Reader, error) {
+	if c.Stdout != nil || c.stdoutPipe != nil {
+		return nil, errors.New("stdout already set")
+	}
+	if c.process != nil {
+		return nil, errors.New("StdoutPipe after process started")
+	}
+
+	c.stdoutPipe = &guestProcessReader{handle: guestStdout}
+	return c.stdoutPipe, nil
+}
+
+// StderrPipe is like StdoutPipe for the process's standard error.
+func (c *GuestCmd) StderrPipe() (io.Reader, error) {
+	if c.Stderr != nil || c.stderrPipe != nil {
+		return nil, errors.New("stderr already set")
+	}
+	if c.process != nil {
+		return nil, errors.New("StderrPipe after process started")
+	}
+
+	c.stderrPipe = &guestProcessReader{handle: guestStderr}
+	return c.stderrPipe, nil
+}
+
+// Start starts the process and the copying of its streams. If the context is
+// done before the process ends, the process is terminated.
+func (c *GuestCmd) Start(ctx context.Context) error {
+	if c.process != nil {
+		return errors.New("already started")
+	}
+
+	var flags []*vboxwebsrv.ProcessCreateFlag
+	if c.Stdout != nil || c.stdoutPipe != nil {
+		flag := vboxwebsrv.ProcessCreateFlagWaitForStdOut
+		flags = append(flags, &flag)
+	}
+	if c.Stderr != nil || c.stderrPipe != nil {
+		flag := vboxwebsrv.ProcessCreateFlagWaitForStdErr
+		flags = append(flags, &flag)
+	}
+
+	process, err := c.session.ProcessCreate(c.Path, c.Args, c.Env, flags, 0)
+	if err != nil {
+		return err
+	}
+	c.process = process
+	c.ctx = ctx
+
+	if c.stdinPipe == nil {
+		w := &guestProcessWriter{process: process, ctx: ctx}
+		c.copy(func() error {
+			if c.Stdin != nil {
+				if _, err := io.Copy(w, c.Stdin); err != nil && err != io.ErrClosedPipe {
+					return err
+				}
+			}
+
+			if err := w.Close(); err != nil {
+				// The process may have exited without reading its input
+				if ended, _ := process.hasEnded(); !ended {
+					return err
+				}
+			}
+
+			return nil
+		})
+	} else {
+		c.stdinPipe.process, c.stdinPipe.ctx = process, ctx
+	}
+
+	for _, stream := range []struct {
+		pipe   *guestProcessReader
+		w      io.Writer
+		handle uint32
+	}{
+		{c.stdoutPipe, c.Stdout, guestStdout},
+		{c.stderrPipe, c.Stderr, guestStderr},
+	} {
+		if stream.pipe != nil {
+			stream.pipe.process, stream.pipe.ctx = process, ctx
+		} else if stream.w != nil {
+			r := &guestProcessReader{process: process, ctx: ctx, handle: stream.handle}
+			w := stream.w
+			c.copy(func() error {
+				_, err := io.Copy(w, r)
+				return err
+			})
+		}
+	}
+
+	return nil
+}
+
+// copy runs fn in the background, recording its error for Wait.
+func (c *GuestCmd) copy(fn func() error) {
+	c.copying.Add(1)
+	go func() {
+		defer c.copying.Done()
+
+		if err := fn(); err != nil {
+			c.copyMu.Lock()
+			if c.copyErr == nil {
+				c.copyErr = err
+			}
+			c.copyMu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for the process to end and for the copying of Stdin, Stdout and
+// Stderr to finish.
+func (c *GuestCmd) Wait() (*GuestProcessResult, error) {
+	if c.process == nil {
+		return nil, errors.New("not started")
+	}
+
+	result, err := c.process.Wait(c.ctx)
+	c.copying.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.copyErr != nil {
+		return result, c.copyErr
+	}
+
+	return result, nil
+}
+
+// Run starts the process and waits for it to end.
+func (c *GuestCmd) Run(ctx context.Context) (*GuestProcessResult, error) {
+	if err := c.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.Wait()
+}
+
+// guestProcessReader reads one of a guest process's output streams. Each Read
+// blocks until the guest produces some output, so a slow consumer holds the
+// guest process back rather than buffering on the host.
+type guestProcessReader struct {
+	process *GuestProcess
+	ctx     context.Context
+	handle  uint32
+
+	ended bool
+}
+
+func (r *guestProcessReader) Read(b []byte) (int, error) {
+	if r.process == nil {
+		return 0, errors.New("process not started")
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	toRead := len(b)
+	if toRead > guestStreamChunkSize {
+		toRead = guestStreamChunkSize
+	}
+
+	for {
+		data, err := r.process.Read(r.handle, uint32(toRead), waitTimeoutMS(r.ctx))
+		if err != nil {
+			return 0, err
+		}
+		if len(data) > 0 {
+			return copy(b, data), nil
+		}
+
+		// Only report the end once a read after the process ended came back
+		// empty, so that output written just before the end is not lost
+		if r.ended {
+			return 0, io.EOF
+		}
+
+		if r.ended, err = r.process.hasEnded(); err != nil {
+			return 0, err
+		}
+
+		if r.ctx.Err() != nil {
+			return 0, contextError(r.ctx)
+		}
+	}
+}
+
+// guestProcessWriter writes to a guest process's standard input. Write only
+// returns once the guest has accepted all of the data.
+type guestProcessWriter struct {
+	process *GuestProcess
+	ctx     context.Context
+}
+
+func (w *guestProcessWriter) Write(b []byte) (int, error) {
+	if w.process == nil {
+		return 0, errors.New("process not started")
+	}
+
+	written := 0
+	for written < len(b) {
+		chunk := b[written:]
+		if len(chunk) > guestStreamChunkSize {
+			chunk = chunk[:guestStreamChunkSize]
+		}
+
+		n, err := w.process.WriteArray(guestStdin, nil, chunk, waitTimeoutMS(w.ctx))
+		if err != nil {
+			return written, err
+		}
+		written += int(n)
+
+		if n == 0 {
+			ended, err := w.process.hasEnded()
+			if err != nil {
+				return written, err
+			}
+			if ended {
+				return written, io.ErrClosedPipe
+			}
+
+			if w.ctx.Err() != nil {
+				return written, contextError(w.ctx)
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// Close signals end-of-file on the process's standard input.
+func (w *guestProcessWriter) Close() error {
+	if w.process == nil {
+		return errors.New("process not started")
+	}
+
+	eof := vboxwebsrv.ProcessInputFlagEndOfFile
+	_, err := w.process.WriteArray(guestStdin, []*vboxwebsrv.ProcessInputFlag{&eof}, nil, waitTimeoutMS(w.ctx))
+	return err
+}
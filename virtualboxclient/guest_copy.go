@@ -0,0 +1,585 @@
+package virtualboxclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// GuestCopyOverwrite says what to do with files that already exist at the
+// destination of a copy.
+type GuestCopyOverwrite int
+
+const (
+	// Replace existing files
+	GuestCopyOverwriteAlways GuestCopyOverwrite = iota
+	// Leave existing files alone
+	GuestCopyOverwriteNever
+	// Replace existing files that are older than the source
+	GuestCopyOverwriteIfNewer
+	// Fail on the first file that exists
+	GuestCopyOverwriteError
+)
+
+// GuestCopyProgress is passed to GuestCopyOptions.Progress after each file.
+// Skipped files count as done. While the server copies the files for
+// ServerLocalPaths, it is passed about once a second instead, with only
+// Source, Dest, Percent and Operation set.
+type GuestCopyProgress struct {
+	Source string
+	Dest   string
+
+	FilesDone  int
+	FilesTotal int
+	BytesDone  int64
+	BytesTotal int64
+
+	// Overall percentage done and the description of the current step of a
+	// copy done by the server
+	Percent   uint32
+	Operation string
+}
+
+// GuestCopyOptions controls CopyToGuest and CopyFromGuest. The zero value
+// copies a single file, replacing the destination, by streaming it through
+// the web service between the client's disk and the guest.
+type GuestCopyOptions struct {
+	// Copy the contents of directories and their subdirectories
+	Recursive bool
+
+	// Copy the files symbolic links point to instead of skipping the links.
+	// Links to directories are never followed.
+	FollowLinks bool
+
+	// Patterns in path.Match syntax matched against the base names of the
+	// files in a recursive copy. If Include is empty all files are included.
+	Include []string
+	Exclude []string
+
+	Overwrite GuestCopyOverwrite
+
+	// Let the VirtualBox server copy the files between the guest and its own
+	// disk. Host paths are then resolved on the host the server runs on, not
+	// on the client's. The server does all the work, so Include and Exclude
+	// are not supported and Overwrite must be GuestCopyOverwriteAlways or
+	// GuestCopyOverwriteIfNewer. If the guest does not implement copying
+	// on the server, the files are streamed instead, provided the host path
+	// also exists on the client.
+	ServerLocalPaths bool
+
+	Progress func(GuestCopyProgress)
+}
+
+func (o *GuestCopyOptions) validate() error {
+	for _, pattern := range append(append([]string{}, o.Include...), o.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matches reports whether a file is selected by Include and Exclude.
+func (o *GuestCopyOptions) matches(name string) bool {
+	for _, pattern := range o.Exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+
+	if len(o.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range o.Include {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyFileFlags returns the flags for a copy done by the server.
+func (o *GuestCopyOptions) copyFileFlags() ([]*vboxwebsrv.CopyFileFlag, error) {
+	if len(o.Include) > 0 || len(o.Exclude) > 0 {
+		return nil, errors.New("Include and Exclude are not supported with ServerLocalPaths")
+	}
+
+	var values []vboxwebsrv.CopyFileFlag
+	switch o.Overwrite {
+	case GuestCopyOverwriteAlways:
+	case GuestCopyOverwriteIfNewer:
+		values = append(values, vboxwebsrv.CopyFileFlagUpdate)
+	default:
+		return nil, errors.New("only GuestCopyOverwriteAlways and GuestCopyOverwriteIfNewer are supported with ServerLocalPaths")
+	}
+	if o.Recursive {
+		values = append(values, vboxwebsrv.CopyFileFlagRecursive)
+	}
+	if o.FollowLinks {
+		values = append(values, vboxwebsrv.CopyFileFlagFollowLinks)
+	}
+
+	flags := make([]*vboxwebsrv.CopyFileFlag, len(values))
+	for i := range values {
+		flags[i] = &values[i]
+	}
+
+	return flags, nil
+}
+
+// guestCopyEntry is a file or directory to be copied.
+type guestCopyEntry struct {
+	source  string
+	dest    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// guestCopy streams a list of files in one direction.
+type guestCopy struct {
+	opts  *GuestCopyOptions
+	files []guestCopyEntry
+
+	destExists  func(dest string) (bool, error)
+	destModTime func(dest string) (time.Time, error)
+	copy        func(ctx context.Context, f guestCopyEntry) error
+}
+
+func (c *guestCopy) run(ctx context.Context) error {
+	progress := GuestCopyProgress{FilesTotal: len(c.files)}
+	for _, f := range c.files {
+		progress.BytesTotal += f.size
+	}
+
+	for _, f := range c.files {
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
+
+		selected, err := c.shouldCopy(f)
+		if err != nil {
+			return err
+		}
+
+		if selected {
+			if err := c.copy(ctx, f); err != nil {
+				return &fs.PathError{Op: "copy", Path: f.source, Err: err}
+			}
+		}
+
+		progress.Source, progress.Dest = f.source, f.dest
+		progress.FilesDone++
+		progress.BytesDone += f.size
+		if c.opts.Progress != nil {
+			c.opts.Progress(progress)
+		}
+	}
+
+	return nil
+}
+
+func (c *guestCopy) shouldCopy(f guestCopyEntry) (bool, error) {
+	if c.opts.Overwrite == GuestCopyOverwriteAlways {
+		return true, nil
+	}
+
+	exists, err := c.destExists(f.dest)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return true, nil
+	}
+
+	switch c.opts.Overwrite {
+	case GuestCopyOverwriteIfNewer:
+		modTime, err := c.destModTime(f.dest)
+		if err != nil {
+			return false, err
+		}
+
+		return f.modTime.After(modTime), nil
+	case GuestCopyOverwriteError:
+		return false, &fs.PathError{Op: "copy", Path: f.dest, Err: fs.ErrExist}
+	}
+
+	return false, nil
+}
+
+// copyServerLocal lets the server copy source to dest with start, which is
+// CopyTo or CopyFrom.
+func copyServerLocal(ctx context.Context, start func(source, dest string, flags []*vboxwebsrv.CopyFileFlag) (*Progress, error), source, dest string, opts *GuestCopyOptions) error {
+	flags, err := opts.copyFileFlags()
+	if err != nil {
+		return err
+	}
+
+	var report func(percent uint32, operation string)
+	if opts.Progress != nil {
+		report = func(percent uint32, operation string) {
+			opts.Progress(GuestCopyProgress{Source: source, Dest: dest, Percent: percent, Operation: operation})
+		}
+	}
+
+	progress, err := start(source, dest, flags)
+	if err == nil {
+		err = progress.wait(ctx, report)
+	}
+	if err != nil {
+		return &fs.PathError{Op: "copy", Path: source, Err: err}
+	}
+
+	return nil
+}
+
+// CopyToGuest copies a file or, with opts.Recursive, a directory from the
+// client's disk, or the server's with opts.ServerLocalPaths, to the guest. A
+// file copied to an existing guest directory keeps its name. The contents of
+// a directory are copied into dest, which is created if needed. A nil opts
+// means the zero GuestCopyOptions.
+func (gs *GuestSession) CopyToGuest(ctx context.Context, source, dest string, opts *GuestCopyOptions) error {
+	if opts == nil {
+		opts = &GuestCopyOptions{}
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	if opts.ServerLocalPaths {
+		err := copyServerLocal(ctx, gs.CopyTo, source, dest, opts)
+		if !isNotImplemented(err) {
+			return err
+		}
+
+		// Stream the files instead if the source is on the client's disk too
+		if _, statErr := os.Stat(source); statErr != nil {
+			return err
+		}
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+
+	var dirs, files []guestCopyEntry
+	if !info.IsDir() {
+		isDir, err := gs.DirectoryExists(dest)
+		if err != nil {
+			return err
+		}
+		if isDir {
+			dest = guestJoin(dest, filepath.Base(source))
+		}
+
+		files = append(files, guestCopyEntry{source, dest, info.Size(), info.Mode().Perm(), info.ModTime()})
+	} else {
+		if !opts.Recursive {
+			return &fs.PathError{Op: "copy", Path: source, Err: errors.New("is a directory")}
+		}
+
+		err := filepath.WalkDir(source, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(source, p)
+			if err != nil {
+				return err
+			}
+
+			target := dest
+			if rel != "." {
+				target = guestJoin(dest, filepath.ToSlash(rel))
+			}
+
+			if d.IsDir() {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+
+				dirs = append(dirs, guestCopyEntry{source: p, dest: target, mode: info.Mode().Perm()})
+				return nil
+			}
+
+			if !opts.matches(d.Name()) {
+				return nil
+			}
+			if d.Type()&fs.ModeSymlink != 0 && !opts.FollowLinks {
+				return nil
+			}
+
+			info, err := os.Stat(p)
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			files = append(files, guestCopyEntry{p, target, info.Size(), info.Mode().Perm(), info.ModTime()})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	parents := vboxwebsrv.DirectoryCreateFlagParents
+	for _, dir := range dirs {
+		if err := gs.DirectoryCreate(dir.dest, uint32(dir.mode), []*vboxwebsrv.DirectoryCreateFlag{&parents}); err != nil {
+			// Creating an existing directory fails on some guests
+			if exists, _ := gs.DirectoryExists(dir.dest); !exists {
+				return &fs.PathError{Op: "mkdir", Path: dir.dest, Err: guestFSError(err)}
+			}
+		}
+	}
+
+	c := &guestCopy{
+		opts:       opts,
+		files:      files,
+		destExists: gs.FileExists,
+		destModTime: func(dest string) (time.Time, error) {
+			info, err := gs.FileQueryInfo(dest)
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			modificationTime, err := info.GetModificationTime()
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			return time.Unix(0, modificationTime), nil
+		},
+		copy: gs.streamToGuest,
+	}
+
+	return c.run(ctx)
+}
+
+// CopyFromGuest copies a file or, with opts.Recursive, a directory from the
+// guest to the client's disk, or the server's with opts.ServerLocalPaths. A
+// file copied to an existing directory keeps its name. The contents of a
+// directory are copied into dest, which is created if needed. A nil opts
+// means the zero GuestCopyOptions.
+func (gs *GuestSession) CopyFromGuest(ctx context.Context, source, dest string, opts *GuestCopyOptions) error {
+	if opts == nil {
+		opts = &GuestCopyOptions{}
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	if opts.ServerLocalPaths {
+		err := copyServerLocal(ctx, gs.CopyFrom, source, dest, opts)
+		if !isNotImplemented(err) {
+			return err
+		}
+
+		// Stream the files instead if dest's directory is on the client's
+		// disk too
+		if info, statErr := os.Stat(filepath.Dir(dest)); statErr != nil || !info.IsDir() {
+			return err
+		}
+	}
+
+	objInfo, err := gs.stat(source)
+	if err != nil {
+		return &fs.PathError{Op: "copy", Path: source, Err: guestFSError(err)}
+	}
+
+	info, err := newGuestFileInfo(objInfo, path.Base(source))
+	if err != nil {
+		return err
+	}
+
+	var files []guestCopyEntry
+	if !info.IsDir() {
+		if localInfo, err := os.Stat(dest); err == nil && localInfo.IsDir() {
+			dest = filepath.Join(dest, path.Base(source))
+		}
+
+		files = append(files, guestCopyEntry{source, dest, info.Size(), info.Mode().Perm(), info.ModTime()})
+	} else {
+		if !opts.Recursive {
+			return &fs.PathError{Op: "copy", Path: source, Err: errors.New("is a directory")}
+		}
+
+		err := fs.WalkDir(gs.FS(source), ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			guestPath := source
+			if p != "." {
+				guestPath = guestJoin(source, p)
+			}
+			target := filepath.Join(dest, filepath.FromSlash(p))
+
+			if d.IsDir() {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+
+				mode := info.Mode().Perm()
+				if mode == 0 {
+					// The guest did not report permissions in a known notation
+					mode = 0755
+				}
+
+				return os.MkdirAll(target, mode|0700)
+			}
+
+			if !opts.matches(d.Name()) {
+				return nil
+			}
+
+			var info fs.FileInfo
+			if d.Type()&fs.ModeSymlink != 0 {
+				if !opts.FollowLinks {
+					return nil
+				}
+
+				objInfo, err := gs.FileQueryInfo(guestPath)
+				if err != nil {
+					// Links to directories and dangling links are skipped
+					return nil
+				}
+
+				if info, err = newGuestFileInfo(objInfo, d.Name()); err != nil {
+					return err
+				}
+			} else if info, err = d.Info(); err != nil {
+				return err
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			files = append(files, guestCopyEntry{guestPath, target, info.Size(), info.Mode().Perm(), info.ModTime()})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	c := &guestCopy{
+		opts:  opts,
+		files: files,
+		destExists: func(dest string) (bool, error) {
+			_, err := os.Stat(dest)
+			if errors.Is(err, fs.ErrNotExist) {
+				return false, nil
+			}
+
+			return err == nil, err
+		},
+		destModTime: func(dest string) (time.Time, error) {
+			info, err := os.Stat(dest)
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			return info.ModTime(), nil
+		},
+		copy: gs.streamFromGuest,
+	}
+
+	return c.run(ctx)
+}
+
+func (gs *GuestSession) streamToGuest(ctx context.Context, f guestCopyEntry) error {
+	src, err := os.Open(f.source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := gs.FileOpen(f.dest, GuestFileOpenModeWrite, GuestFileDispositionCreateAlways, uint32(f.mode))
+	if err != nil {
+		return guestFSError(err)
+	}
+
+	if err := copyWithContext(ctx, dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+func (gs *GuestSession) streamFromGuest(ctx context.Context, f guestCopyEntry) error {
+	src, err := gs.FileOpen(f.source, GuestFileOpenModeRead, GuestFileDispositionOpenExisting, 0)
+	if err != nil {
+		return guestFSError(err)
+	}
+	defer src.Close()
+
+	mode := f.mode
+	if mode == 0 {
+		mode = 0644
+	}
+
+	dst, err := os.OpenFile(f.dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if err := copyWithContext(ctx, dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+// copyWithContext is io.Copy in chunks of guestStreamChunkSize, checking the
+// context between chunks.
+func copyWithContext(ctx context.Context, dst io.Writer, src io.Reader) error {
+	buf := make([]byte, guestStreamChunkSize)
+	for {
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
+
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// isNotImplemented reports whether err is an E_NOTIMPL fault or result code.
+func isNotImplemented(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "0x80004001") || strings.Contains(message, "not implemented")
+}
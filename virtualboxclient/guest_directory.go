@@ -0,0 +1,154 @@
+package virtualboxclient
+
+import (
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// GuestDirectory is a directory opened for listing in a guest session.
+type GuestDirectory struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (gs *GuestSession) DirectoryCreate(path string, mode uint32, flags []*vboxwebsrv.DirectoryCreateFlag) error {
+	request := vboxwebsrv.IGuestSessiondirectoryCreate{This: gs.managedObjectId, Path: path, Mode: mode, Flags: flags}
+
+	_, err := gs.virtualbox.IGuestSessiondirectoryCreate(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// DirectoryCreateTemp creates a directory named after templateName, in which
+// a run of at least three "X" is replaced, below path and returns its path.
+// A secure directory is only accessible by the session's user.
+func (gs *GuestSession) DirectoryCreateTemp(templateName string, mode uint32, path string, secure bool) (string, error) {
+	request := vboxwebsrv.IGuestSessiondirectoryCreateTemp{This: gs.managedObjectId, TemplateName: templateName, Mode: mode, Path: path, Secure: secure}
+
+	response, err := gs.virtualbox.IGuestSessiondirectoryCreateTemp(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (gs *GuestSession) DirectoryExists(path string) (bool, error) {
+	request := vboxwebsrv.IGuestSessiondirectoryExists{This: gs.managedObjectId, Path: path}
+
+	response, err := gs.virtualbox.IGuestSessiondirectoryExists(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// DirectoryOpen opens a directory for listing. An empty filter lists all
+// entries.
+func (gs *GuestSession) DirectoryOpen(path, filter string, flags []*vboxwebsrv.DirectoryOpenFlag) (*GuestDirectory, error) {
+	request := vboxwebsrv.IGuestSessiondirectoryOpen{This: gs.managedObjectId, Path: path, Filter: filter, Flags: flags}
+
+	response, err := gs.virtualbox.IGuestSessiondirectoryOpen(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &GuestDirectory{gs.virtualbox, response.Returnval}, nil
+}
+
+// DirectoryQueryInfo fails if path is not a directory.
+func (gs *GuestSession) DirectoryQueryInfo(path string) (*FsObjInfo, error) {
+	request := vboxwebsrv.IGuestSessiondirectoryQueryInfo{This: gs.managedObjectId, Path: path}
+
+	response, err := gs.virtualbox.IGuestSessiondirectoryQueryInfo(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &FsObjInfo{gs.virtualbox, response.Returnval}, nil
+}
+
+// DirectoryRemove removes an empty directory.
+func (gs *GuestSession) DirectoryRemove(path string) error {
+	request := vboxwebsrv.IGuestSessiondirectoryRemove{This: gs.managedObjectId, Path: path}
+
+	_, err := gs.virtualbox.IGuestSessiondirectoryRemove(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (gs *GuestSession) DirectoryRemoveRecursive(path string, flags []*vboxwebsrv.DirectoryRemoveRecFlag) (*Progress, error) {
+	request := vboxwebsrv.IGuestSessiondirectoryRemoveRecursive{This: gs.managedObjectId, Path: path, Flags: flags}
+
+	response, err := gs.virtualbox.IGuestSessiondirectoryRemoveRecursive(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{gs.virtualbox, response.Returnval}, nil
+}
+
+func (gs *GuestSession) DirectoryRename(source, dest string, flags []*vboxwebsrv.PathRenameFlag) error {
+	request := vboxwebsrv.IGuestSessiondirectoryRename{This: gs.managedObjectId, Source: source, Dest: dest, Flags: flags}
+
+	_, err := gs.virtualbox.IGuestSessiondirectoryRename(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// FileQueryInfo fails if path is not a regular file.
+func (gs *GuestSession) FileQueryInfo(path string) (*FsObjInfo, error) {
+	request := vboxwebsrv.IGuestSessionfileQueryInfo{This: gs.managedObjectId, Path: path}
+
+	response, err := gs.virtualbox.IGuestSessionfileQueryInfo(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &FsObjInfo{gs.virtualbox, response.Returnval}, nil
+}
+
+func (d *GuestDirectory) Close() error {
+	request := vboxwebsrv.IDirectoryclose{This: d.managedObjectId}
+
+	_, err := d.virtualbox.IDirectoryclose(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// Read returns the next entry of the directory, or nil once all entries have
+// been read.
+func (d *GuestDirectory) Read() (*FsObjInfo, error) {
+	request := vboxwebsrv.IDirectoryread{This: d.managedObjectId}
+
+	response, err := d.virtualbox.IDirectoryread(&request)
+	if err != nil {
+		// The end of the listing is reported as VBOX_E_OBJECT_NOT_FOUND
+		if isObjectNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &FsObjInfo{d.virtualbox, response.Returnval}, nil
+}
+
+// isObjectNotFound reports whether err is a VBOX_E_OBJECT_NOT_FOUND fault.
+func isObjectNotFound(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "0x80bb0001")
+}
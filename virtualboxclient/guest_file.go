@@ -0,0 +1,356 @@
+package virtualboxclient
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// GuestFileOpenMode is the access a guest file is opened with.
+type GuestFileOpenMode string
+
+const (
+	GuestFileOpenModeRead      GuestFileOpenMode = "r"
+	GuestFileOpenModeWrite     GuestFileOpenMode = "w"
+	GuestFileOpenModeReadWrite GuestFileOpenMode = "w+"
+)
+
+// GuestFileDisposition says what to do when a guest file does or does not
+// exist yet.
+type GuestFileDisposition string
+
+const (
+	// Create the file, replacing an existing one
+	GuestFileDispositionCreateAlways GuestFileDisposition = "ca"
+	// Create the file, failing if it exists
+	GuestFileDispositionCreateNew GuestFileDisposition = "ce"
+	// Open or create the file and position writes at its end
+	GuestFileDispositionOpenAppend GuestFileDisposition = "oa"
+	// Open the file, creating it if it does not exist
+	GuestFileDispositionOpenOrCreate GuestFileDisposition = "oc"
+	// Open the file, failing if it does not exist
+	GuestFileDispositionOpenExisting GuestFileDisposition = "oe"
+	// Open and truncate the file, failing if it does not exist
+	GuestFileDispositionOpenTruncate GuestFileDisposition = "ot"
+)
+
+// How long a single read or write of a guest file may take
+const guestFileTimeoutMS = 30000
+
+// GuestFile is a file opened in a guest session. It implements io.Reader,
+// io.Writer, io.Seeker, io.ReaderAt, io.WriterAt and io.Closer.
+type GuestFile struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+
+	// Used to find the size of the file for seeking relative to its end
+	session *GuestSession
+	path    string
+}
+
+func (gs *GuestSession) FileExists(path string) (bool, error) {
+	request := vboxwebsrv.IGuestSessionfileExists{This: gs.managedObjectId, Path: path}
+
+	response, err := gs.virtualbox.IGuestSessionfileExists(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// FileOpen opens a file in the guest. The creation mode holds the permission
+// bits of a newly created file, e.g. 0644.
+func (gs *GuestSession) FileOpen(path string, openMode GuestFileOpenMode, disposition GuestFileDisposition, creationMode uint32) (*GuestFile, error) {
+	request := vboxwebsrv.IGuestSessionfileOpen{This: gs.managedObjectId, Path: path, OpenMode: string(openMode), Disposition: string(disposition), CreationMode: creationMode}
+
+	response, err := gs.virtualbox.IGuestSessionfileOpen(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &GuestFile{gs.virtualbox, response.Returnval, gs, path}, nil
+}
+
+// FileOpenEx is like FileOpen with a sharing mode, which guests other than
+// Windows ignore, and the initial offset.
+func (gs *GuestSession) FileOpenEx(path string, openMode GuestFileOpenMode, disposition GuestFileDisposition, sharingMode string, creationMode uint32, offset int64) (*GuestFile, error) {
+	request := vboxwebsrv.IGuestSessionfileOpenEx{This: gs.managedObjectId, Path: path, OpenMode: string(openMode), Disposition: string(disposition), SharingMode: sharingMode, CreationMode: creationMode, Offset: offset}
+
+	response, err := gs.virtualbox.IGuestSessionfileOpenEx(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &GuestFile{gs.virtualbox, response.Returnval, gs, path}, nil
+}
+
+func (gs *GuestSession) FileQuerySize(path string) (int64, error) {
+	request := vboxwebsrv.IGuestSessionfileQuerySize{This: gs.managedObjectId, Path: path}
+
+	response, err := gs.virtualbox.IGuestSessionfileQuerySize(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (gs *GuestSession) FileRemove(path string) error {
+	request := vboxwebsrv.IGuestSessionfileRemove{This: gs.managedObjectId, Path: path}
+
+	_, err := gs.virtualbox.IGuestSessionfileRemove(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (gs *GuestSession) FileRename(source, dest string, flags []*vboxwebsrv.PathRenameFlag) error {
+	request := vboxwebsrv.IGuestSessionfileRename{This: gs.managedObjectId, Source: source, Dest: dest, Flags: flags}
+
+	_, err := gs.virtualbox.IGuestSessionfileRename(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (f *GuestFile) Close() error {
+	request := vboxwebsrv.IFileclose{This: f.managedObjectId}
+
+	_, err := f.virtualbox.IFileclose(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (f *GuestFile) GetFileName() (string, error) {
+	request := vboxwebsrv.IFilegetFileName{This: f.managedObjectId}
+
+	response, err := f.virtualbox.IFilegetFileName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (f *GuestFile) GetInitialSize() (int64, error) {
+	request := vboxwebsrv.IFilegetInitialSize{This: f.managedObjectId}
+
+	response, err := f.virtualbox.IFilegetInitialSize(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (f *GuestFile) GetOffset() (int64, error) {
+	request := vboxwebsrv.IFilegetOffset{This: f.managedObjectId}
+
+	response, err := f.virtualbox.IFilegetOffset(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (f *GuestFile) GetStatus() (*vboxwebsrv.FileStatus, error) {
+	request := vboxwebsrv.IFilegetStatus{This: f.managedObjectId}
+
+	response, err := f.virtualbox.IFilegetStatus(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (f *GuestFile) QueryInfo() (*FsObjInfo, error) {
+	request := vboxwebsrv.IFilequeryInfo{This: f.managedObjectId}
+
+	response, err := f.virtualbox.IFilequeryInfo(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &FsObjInfo{f.virtualbox, response.Returnval}, nil
+}
+
+// Read reads from the current offset. It returns io.EOF at the end of the
+// file.
+func (f *GuestFile) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	request := vboxwebsrv.IFileread{This: f.managedObjectId, ToRead: guestChunkSize(len(b)), TimeoutMS: guestFileTimeoutMS}
+
+	response, err := f.virtualbox.IFileread(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	data, err := base64.StdEncoding.DecodeString(response.Returnval)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, io.EOF
+	}
+
+	return copy(b, data), nil
+}
+
+// ReadAt reads len(b) bytes from the given offset, returning io.EOF if the
+// file ends first.
+func (f *GuestFile) ReadAt(b []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	n := 0
+	for n < len(b) {
+		request := vboxwebsrv.IFilereadAt{This: f.managedObjectId, Offset: offset + int64(n), ToRead: guestChunkSize(len(b) - n), TimeoutMS: guestFileTimeoutMS}
+
+		response, err := f.virtualbox.IFilereadAt(&request)
+		if err != nil {
+			return n, err // TODO: Wrap the error
+		}
+
+		data, err := base64.StdEncoding.DecodeString(response.Returnval)
+		if err != nil {
+			return n, err
+		}
+		if len(data) == 0 {
+			return n, io.EOF
+		}
+
+		n += copy(b[n:], data)
+	}
+
+	return n, nil
+}
+
+// Seek sets the offset of the next Read or Write. Seeking relative to the end
+// queries the size of the file first.
+func (f *GuestFile) Seek(offset int64, whence int) (int64, error) {
+	var seekType vboxwebsrv.FileSeekType
+	switch whence {
+	case io.SeekStart:
+		seekType = vboxwebsrv.FileSeekTypeSet
+	case io.SeekCurrent:
+		seekType = vboxwebsrv.FileSeekTypeCurrent
+	case io.SeekEnd:
+		// The API can only seek relative to the start or the current offset
+		size, err := f.session.FileQuerySize(f.path)
+		if err != nil {
+			return 0, err
+		}
+
+		seekType = vboxwebsrv.FileSeekTypeSet
+		offset += size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+
+	if seekType == vboxwebsrv.FileSeekTypeSet && offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	request := vboxwebsrv.IFileseek{This: f.managedObjectId, Offset: offset, Whence: &seekType}
+
+	_, err := f.virtualbox.IFileseek(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return f.GetOffset()
+}
+
+// Stat returns the file's current size, mode and modification time.
+func (f *GuestFile) Stat() (fs.FileInfo, error) {
+	info, err := f.QueryInfo()
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: f.path, Err: guestFSError(err)}
+	}
+
+	name := f.path
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	fileInfo, err := newGuestFileInfo(info, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: f.path, Err: err}
+	}
+
+	return fileInfo, nil
+}
+
+// Write writes all of b at the current offset.
+func (f *GuestFile) Write(b []byte) (int, error) {
+	n := 0
+	for n < len(b) {
+		chunk := b[n : n+int(guestChunkSize(len(b)-n))]
+		request := vboxwebsrv.IFilewrite{This: f.managedObjectId, Data: base64.StdEncoding.EncodeToString(chunk), TimeoutMS: guestFileTimeoutMS}
+
+		response, err := f.virtualbox.IFilewrite(&request)
+		if err != nil {
+			return n, err // TODO: Wrap the error
+		}
+		if response.Returnval == 0 {
+			return n, io.ErrShortWrite
+		}
+
+		n += int(response.Returnval)
+	}
+
+	return n, nil
+}
+
+// WriteAt writes all of b at the given offset.
+func (f *GuestFile) WriteAt(b []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	n := 0
+	for n < len(b) {
+		chunk := b[n : n+int(guestChunkSize(len(b)-n))]
+		request := vboxwebsrv.IFilewriteAt{This: f.managedObjectId, Offset: offset + int64(n), Data: base64.StdEncoding.EncodeToString(chunk), TimeoutMS: guestFileTimeoutMS}
+
+		response, err := f.virtualbox.IFilewriteAt(&request)
+		if err != nil {
+			return n, err // TODO: Wrap the error
+		}
+		if response.Returnval == 0 {
+			return n, io.ErrShortWrite
+		}
+
+		n += int(response.Returnval)
+	}
+
+	return n, nil
+}
+
+// guestChunkSize limits the size of a single transfer to guestStreamChunkSize.
+func guestChunkSize(n int) uint32 {
+	if n > guestStreamChunkSize {
+		n = guestStreamChunkSize
+	}
+
+	return uint32(n)
+}
@@ -0,0 +1,331 @@
+package virtualboxclient
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// GuestFS is a read-only view of a guest's file system through a guest
+// session. It implements fs.FS, fs.ReadDirFS and fs.StatFS.
+type GuestFS struct {
+	session *GuestSession
+	root    string
+}
+
+// FS returns the guest file system below root, e.g. "/" or "C:/". Names are
+// joined to the root with forward slashes.
+func (gs *GuestSession) FS(root string) *GuestFS {
+	return &GuestFS{session: gs, root: root}
+}
+
+func (gfs *GuestFS) guestPath(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return gfs.root, nil
+	}
+
+	return guestJoin(gfs.root, name), nil
+}
+
+func (gfs *GuestFS) Open(name string) (fs.File, error) {
+	guestPath, err := gfs.guestPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := gfs.session.stat(guestPath)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: guestFSError(err)}
+	}
+
+	fileInfo, err := newGuestFileInfo(info, path.Base(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	if fileInfo.IsDir() {
+		return &guestFSDir{gfs: gfs, name: name, guestPath: guestPath, info: fileInfo}, nil
+	}
+
+	file, err := gfs.session.FileOpen(guestPath, GuestFileOpenModeRead, GuestFileDispositionOpenExisting, 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: guestFSError(err)}
+	}
+
+	return &guestFSFile{file, fileInfo}, nil
+}
+
+func (gfs *GuestFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	guestPath, err := gfs.guestPath("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := gfs.readDir(guestPath)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: guestFSError(err)}
+	}
+
+	return entries, nil
+}
+
+// readDir lists a guest directory sorted by name, without "." and "..".
+func (gfs *GuestFS) readDir(guestPath string) ([]fs.DirEntry, error) {
+	dir, err := gfs.session.DirectoryOpen(guestPath, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	entries := []fs.DirEntry{}
+	for {
+		info, err := dir.Read()
+		if err != nil {
+			return nil, err
+		}
+		if info == nil {
+			break
+		}
+
+		fileInfo, err := newGuestFileInfo(info, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if fileInfo.name == "." || fileInfo.name == ".." {
+			continue
+		}
+
+		entries = append(entries, fs.FileInfoToDirEntry(fileInfo))
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries, nil
+}
+
+func (gfs *GuestFS) Stat(name string) (fs.FileInfo, error) {
+	guestPath, err := gfs.guestPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := gfs.session.stat(guestPath)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: guestFSError(err)}
+	}
+
+	fileInfo, err := newGuestFileInfo(info, path.Base(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+
+	return fileInfo, nil
+}
+
+// guestJoin joins a slash-separated name to a guest directory.
+func guestJoin(dir, name string) string {
+	return strings.TrimSuffix(dir, "/") + "/" + name
+}
+
+// stat queries a path that may be a file or a directory.
+func (gs *GuestSession) stat(guestPath string) (*FsObjInfo, error) {
+	info, err := gs.FileQueryInfo(guestPath)
+	if err == nil {
+		return info, nil
+	}
+
+	if info, dirErr := gs.DirectoryQueryInfo(guestPath); dirErr == nil {
+		return info, nil
+	}
+
+	return nil, err
+}
+
+// guestFSError maps the errors VirtualBox reports for missing and inaccessible
+// paths to fs.ErrNotExist and fs.ErrPermission.
+func guestFSError(err error) error {
+	message := strings.ToLower(err.Error())
+
+	for _, s := range []string{"verr_file_not_found", "verr_path_not_found", "0x80bb0001"} {
+		if strings.Contains(message, s) {
+			return fs.ErrNotExist
+		}
+	}
+
+	if strings.Contains(message, "verr_access_denied") || strings.Contains(message, "verr_permission_denied") {
+		return fs.ErrPermission
+	}
+
+	return err
+}
+
+// guestFileInfo implements fs.FileInfo with the values of an FsObjInfo, which
+// Sys returns.
+type guestFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	sys     *FsObjInfo
+}
+
+var fsObjTypeModes = map[vboxwebsrv.FsObjType]fs.FileMode{
+	vboxwebsrv.FsObjTypeFile:      0,
+	vboxwebsrv.FsObjTypeDirectory: fs.ModeDir,
+	vboxwebsrv.FsObjTypeSymlink:   fs.ModeSymlink,
+	vboxwebsrv.FsObjTypeFIFO:      fs.ModeNamedPipe,
+	vboxwebsrv.FsObjTypeSocket:    fs.ModeSocket,
+	vboxwebsrv.FsObjTypeDevChar:   fs.ModeDevice | fs.ModeCharDevice,
+	vboxwebsrv.FsObjTypeDevBlock:  fs.ModeDevice,
+}
+
+// newGuestFileInfo reads the properties of info. An empty name means the name
+// reported by the guest.
+func newGuestFileInfo(info *FsObjInfo, name string) (*guestFileInfo, error) {
+	fi := &guestFileInfo{name: name, sys: info}
+	var err error
+
+	if fi.name == "" {
+		if fi.name, err = info.GetName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if fi.size, err = info.GetObjectSize(); err != nil {
+		return nil, err
+	}
+
+	objType, err := info.GetType()
+	if err != nil {
+		return nil, err
+	}
+
+	fi.mode = fs.ModeIrregular
+	if objType != nil {
+		if mode, ok := fsObjTypeModes[*objType]; ok {
+			fi.mode = mode
+		}
+	}
+
+	attributes, err := info.GetFileAttributes()
+	if err != nil {
+		return nil, err
+	}
+	fi.mode |= parsePermissions(attributes)
+
+	modificationTime, err := info.GetModificationTime()
+	if err != nil {
+		return nil, err
+	}
+	fi.modTime = time.Unix(0, modificationTime)
+
+	return fi, nil
+}
+
+// parsePermissions returns the permission bits of attributes in "ls -l"
+// notation, or 0 if the guest uses some other notation.
+func parsePermissions(attributes string) fs.FileMode {
+	attributes = strings.TrimSpace(attributes)
+	if len(attributes) == 10 {
+		attributes = attributes[1:]
+	}
+	if len(attributes) != 9 {
+		return 0
+	}
+
+	var perm fs.FileMode
+	for i, c := range attributes {
+		switch {
+		case c == '-':
+		case c == rune("rwxrwxrwx"[i]):
+			perm |= 1 << uint(8-i)
+		case i%3 == 2 && strings.ContainsRune("sStT", c):
+			// setuid, setgid and sticky bits imply execute only in lower case
+			if c == 's' || c == 't' {
+				perm |= 1 << uint(8-i)
+			}
+		default:
+			return 0
+		}
+	}
+
+	return perm
+}
+
+func (fi *guestFileInfo) Name() string       { return fi.name }
+func (fi *guestFileInfo) Size() int64        { return fi.size }
+func (fi *guestFileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi *guestFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *guestFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *guestFileInfo) Sys() interface{}   { return fi.sys }
+
+type guestFSFile struct {
+	*GuestFile
+	info *guestFileInfo
+}
+
+func (f *guestFSFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// guestFSDir implements fs.ReadDirFile. The listing is fetched on the first
+// call to ReadDir.
+type guestFSDir struct {
+	gfs       *GuestFS
+	name      string
+	guestPath string
+	info      *guestFileInfo
+
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *guestFSDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *guestFSDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *guestFSDir) Close() error {
+	return nil
+}
+
+func (d *guestFSDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		entries, err := d.gfs.readDir(d.guestPath)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: guestFSError(err)}
+		}
+		d.entries = entries
+	}
+
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+
+	return rest[:n], nil
+}
@@ -0,0 +1,45 @@
+package virtualboxclient
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestParsePermissions(t *testing.T) {
+	tests := []struct {
+		attributes string
+		want       fs.FileMode
+	}{
+		// ls -l notation, with and without the type character
+		{"-rw-r--r--", 0644},
+		{"drwxr-xr-x", 0755},
+		{"lrwxrwxrwx", 0777},
+		{"rwxr-x---", 0750},
+		{"----------", 0},
+		{" -rw------- ", 0600},
+
+		// Setuid, setgid and sticky bits only imply execute in lower case
+		{"-rwsr-xr-x", 0755},
+		{"-rwSr--r--", 0644},
+		{"-rwxr-sr-x", 0755},
+		{"drwxrwxrwt", 0777},
+		{"drwxrwxrwT", 0776},
+
+		// Windows guests report DOS attributes, which carry no permissions
+		{"", 0},
+		{"A", 0},
+		{"RHSA", 0},
+		{"-----A----", 0},
+		{"d---------r", 0},
+
+		// Characters in the wrong position are not permissions
+		{"-wrxr-xr-x", 0},
+		{"-rsxr-xr-x", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parsePermissions(tt.attributes); got != tt.want {
+			t.Errorf("parsePermissions(%q) = %#o, want %#o", tt.attributes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package virtualboxclient
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// The methods in this file follow the semantics of their namesakes in the os
+// package. Their errors are *fs.PathError or *os.LinkError values, with
+// missing and inaccessible paths reported as fs.ErrNotExist and
+// fs.ErrPermission.
+
+// Exists reports whether path names a file or directory in the guest.
+func (gs *GuestSession) Exists(path string) (bool, error) {
+	exists, err := gs.FileExists(path)
+	if err == nil && !exists {
+		exists, err = gs.DirectoryExists(path)
+	}
+	if err != nil {
+		return false, &fs.PathError{Op: "stat", Path: path, Err: guestFSError(err)}
+	}
+
+	return exists, nil
+}
+
+// MkdirAll creates a directory along with any missing parents. It does
+// nothing if the directory already exists.
+func (gs *GuestSession) MkdirAll(path string, perm fs.FileMode) error {
+	parents := vboxwebsrv.DirectoryCreateFlagParents
+
+	err := gs.DirectoryCreate(path, uint32(perm.Perm()), []*vboxwebsrv.DirectoryCreateFlag{&parents})
+	if err == nil {
+		return nil
+	}
+
+	if isDir, dirErr := gs.DirectoryExists(path); dirErr == nil && isDir {
+		return nil
+	}
+
+	return &fs.PathError{Op: "mkdir", Path: path, Err: guestFSError(err)}
+}
+
+// MkdirTemp creates a new directory only accessible by the session's user in
+// dir and returns its path. A random string is appended to pattern, or
+// replaces a trailing "*". Unlike os.MkdirTemp the random string cannot be in
+// the middle of the name, and dir must be given as the guest's temporary
+// directory is not known.
+func (gs *GuestSession) MkdirTemp(dir, pattern string) (string, error) {
+	if dir == "" {
+		return "", &fs.PathError{Op: "mkdirtemp", Path: pattern, Err: errors.New("no directory given")}
+	}
+	if strings.ContainsAny(pattern, `/\`) {
+		return "", &fs.PathError{Op: "mkdirtemp", Path: pattern, Err: errors.New("pattern contains path separator")}
+	}
+
+	// The guest replaces a run of "X" at the end of the template
+	template := strings.TrimSuffix(pattern, "*")
+	if strings.Contains(template, "*") {
+		return "", &fs.PathError{Op: "mkdirtemp", Path: pattern, Err: errors.New("pattern may only end with *")}
+	}
+	template += "XXXXXX"
+
+	path, err := gs.DirectoryCreateTemp(template, 0700, dir, true)
+	if err != nil {
+		return "", &fs.PathError{Op: "mkdirtemp", Path: guestJoin(dir, template), Err: guestFSError(err)}
+	}
+
+	return path, nil
+}
+
+// Readlink returns the target of a symbolic link.
+func (gs *GuestSession) Readlink(name string) (string, error) {
+	target, err := gs.SymlinkRead(name, nil)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: guestFSError(err)}
+	}
+
+	return target, nil
+}
+
+// RemoveAll removes path and, if it is a directory, everything it contains.
+// A symbolic link is removed itself, not what it points to. It returns nil if
+// path does not exist. If the context is done before a directory has been
+// removed, the removal is cancelled.
+func (gs *GuestSession) RemoveAll(ctx context.Context, path string) error {
+	isLink, err := gs.isSymlink(path)
+	if err != nil {
+		return &fs.PathError{Op: "unlinkat", Path: path, Err: guestFSError(err)}
+	}
+
+	if isLink {
+		// The guest decides whether a link to a directory is removed as a
+		// file or a directory
+		err := gs.SymlinkRemoveFile(path)
+		if err != nil {
+			err = gs.SymlinkRemoveDirectory(path)
+		}
+		if err != nil {
+			return &fs.PathError{Op: "unlinkat", Path: path, Err: guestFSError(err)}
+		}
+
+		return nil
+	}
+
+	isDir, err := gs.DirectoryExists(path)
+	if err != nil {
+		return &fs.PathError{Op: "unlinkat", Path: path, Err: guestFSError(err)}
+	}
+
+	if !isDir {
+		err := gs.FileRemove(path)
+		if err != nil && guestFSError(err) != fs.ErrNotExist {
+			return &fs.PathError{Op: "unlinkat", Path: path, Err: guestFSError(err)}
+		}
+
+		return nil
+	}
+
+	contentAndDir := vboxwebsrv.DirectoryRemoveRecFlagContentAndDir
+	progress, err := gs.DirectoryRemoveRecursive(path, []*vboxwebsrv.DirectoryRemoveRecFlag{&contentAndDir})
+	if err == nil {
+		err = progress.Wait(ctx)
+	}
+	if err != nil {
+		return &fs.PathError{Op: "unlinkat", Path: path, Err: guestFSError(err)}
+	}
+
+	return nil
+}
+
+// Rename renames a file or directory, replacing an existing file at newpath.
+func (gs *GuestSession) Rename(oldpath, newpath string) error {
+	replace := vboxwebsrv.PathRenameFlagReplace
+	flags := []*vboxwebsrv.PathRenameFlag{&replace}
+
+	isDir, err := gs.DirectoryExists(oldpath)
+	if err == nil {
+		if isDir {
+			err = gs.DirectoryRename(oldpath, newpath, flags)
+		} else {
+			err = gs.FileRename(oldpath, newpath, flags)
+		}
+	}
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: guestFSError(err)}
+	}
+
+	return nil
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+func (gs *GuestSession) Symlink(oldname, newname string) error {
+	if err := gs.SymlinkCreate(newname, oldname, vboxwebsrv.SymlinkTypeUnknown); err != nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: guestFSError(err)}
+	}
+
+	return nil
+}
+
+// isSymlink reports whether path is a symbolic link. Guests that do not
+// implement SymlinkExists are asked for the type of the entry in the parent
+// directory's listing, which does not follow links.
+func (gs *GuestSession) isSymlink(path string) (bool, error) {
+	isLink, err := gs.SymlinkExists(path)
+	if !isNotImplemented(err) {
+		return isLink, err
+	}
+
+	trimmed := strings.TrimRight(path, `/\`)
+	i := strings.LastIndexAny(trimmed, `/\`)
+	if i < 0 {
+		return false, nil
+	}
+	parent, name := trimmed[:i+1], trimmed[i+1:]
+
+	dir, err := gs.DirectoryOpen(parent, name, nil)
+	if err != nil {
+		if guestFSError(err) == fs.ErrNotExist {
+			return false, nil
+		}
+
+		return false, err
+	}
+	defer dir.Close()
+
+	for {
+		info, err := dir.Read()
+		if err != nil || info == nil {
+			return false, err
+		}
+
+		entryName, err := info.GetName()
+		if err != nil {
+			return false, err
+		}
+		if entryName != name {
+			continue
+		}
+
+		objType, err := info.GetType()
+		if err != nil {
+			return false, err
+		}
+
+		return objType != nil && *objType == vboxwebsrv.FsObjTypeSymlink, nil
+	}
+}
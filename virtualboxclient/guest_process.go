@@ -0,0 +1,165 @@
+package virtualboxclient
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// GuestProcessResult is how a guest process ended. For processes killed by a
+// signal the exit code is the signal number.
+type GuestProcessResult struct {
+	ExitCode int32
+	Status   vboxwebsrv.ProcessStatus
+}
+
+type GuestProcess struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (p *GuestProcess) GetExitCode() (int32, error) {
+	request := vboxwebsrv.IProcessgetExitCode{This: p.managedObjectId}
+
+	response, err := p.virtualbox.IProcessgetExitCode(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (p *GuestProcess) GetPID() (uint32, error) {
+	request := vboxwebsrv.IProcessgetPID{This: p.managedObjectId}
+
+	response, err := p.virtualbox.IProcessgetPID(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (p *GuestProcess) GetStatus() (*vboxwebsrv.ProcessStatus, error) {
+	request := vboxwebsrv.IProcessgetStatus{This: p.managedObjectId}
+
+	response, err := p.virtualbox.IProcessgetStatus(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// Read returns up to toRead bytes of the output on handle (1 for stdout, 2 for
+// stderr), waiting at most timeoutMS for some to arrive. The output is only
+// kept if the process was created with ProcessCreateFlagWaitForStdOut or
+// ProcessCreateFlagWaitForStdErr.
+func (p *GuestProcess) Read(handle, toRead, timeoutMS uint32) ([]byte, error) {
+	request := vboxwebsrv.IProcessread{This: p.managedObjectId, Handle: handle, ToRead: toRead, TimeoutMS: timeoutMS}
+
+	response, err := p.virtualbox.IProcessread(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return base64.StdEncoding.DecodeString(response.Returnval)
+}
+
+func (p *GuestProcess) Terminate() error {
+	request := vboxwebsrv.IProcessterminate{This: p.managedObjectId}
+
+	_, err := p.virtualbox.IProcessterminate(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+var processEndedStatuses = map[vboxwebsrv.ProcessStatus]bool{
+	vboxwebsrv.ProcessStatusTerminatedNormally:   true,
+	vboxwebsrv.ProcessStatusTerminatedSignal:     true,
+	vboxwebsrv.ProcessStatusTerminatedAbnormally: true,
+	vboxwebsrv.ProcessStatusTimedOutKilled:       true,
+	vboxwebsrv.ProcessStatusTimedOutAbnormally:   true,
+	vboxwebsrv.ProcessStatusDown:                 true,
+	vboxwebsrv.ProcessStatusError:                true,
+}
+
+// hasEnded reports whether the process is no longer running.
+func (p *GuestProcess) hasEnded() (bool, error) {
+	status, err := p.GetStatus()
+	if err != nil {
+		return false, err
+	}
+
+	return status != nil && processEndedStatuses[*status], nil
+}
+
+// Wait waits for the process to end. If the context is done first the process
+// is terminated.
+func (p *GuestProcess) Wait(ctx context.Context) (*GuestProcessResult, error) {
+	terminate := vboxwebsrv.ProcessWaitForFlagTerminate
+
+	var status vboxwebsrv.ProcessStatus
+	for {
+		if _, err := p.WaitForArray([]*vboxwebsrv.ProcessWaitForFlag{&terminate}, waitTimeoutMS(ctx)); err != nil {
+			return nil, classifyGuestError(err)
+		}
+
+		s, err := p.GetStatus()
+		if err != nil {
+			return nil, err
+		}
+		if s != nil && processEndedStatuses[*s] {
+			status = *s
+			break
+		}
+
+		if ctx.Err() != nil {
+			p.Terminate()
+			return nil, contextError(ctx)
+		}
+	}
+
+	switch status {
+	case vboxwebsrv.ProcessStatusTimedOutKilled, vboxwebsrv.ProcessStatusTimedOutAbnormally:
+		return nil, ErrGuestTimeout
+	case vboxwebsrv.ProcessStatusDown, vboxwebsrv.ProcessStatusError:
+		return nil, fmt.Errorf("guest process failed: %s", status)
+	}
+
+	exitCode, err := p.GetExitCode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GuestProcessResult{ExitCode: exitCode, Status: status}, nil
+}
+
+func (p *GuestProcess) WaitForArray(waitFor []*vboxwebsrv.ProcessWaitForFlag, timeoutMS uint32) (*vboxwebsrv.ProcessWaitResult, error) {
+	request := vboxwebsrv.IProcesswaitForArray{This: p.managedObjectId, WaitFor: waitFor, TimeoutMS: timeoutMS}
+
+	response, err := p.virtualbox.IProcesswaitForArray(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// WriteArray writes data to the input on handle (0 for stdin) and returns how
+// many bytes the guest accepted within timeoutMS.
+func (p *GuestProcess) WriteArray(handle uint32, flags []*vboxwebsrv.ProcessInputFlag, data []byte, timeoutMS uint32) (uint32, error) {
+	request := vboxwebsrv.IProcesswriteArray{This: p.managedObjectId, Handle: handle, Flags: flags, Data: base64.StdEncoding.EncodeToString(data), TimeoutMS: timeoutMS}
+
+	response, err := p.virtualbox.IProcesswriteArray(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
@@ -0,0 +1,334 @@
+package virtualboxclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// GuestPropertyFlag restricts how a guest property may be used.
+type GuestPropertyFlag string
+
+const (
+	// Deleted when the machine powers off
+	GuestPropertyTransient GuestPropertyFlag = "TRANSIENT"
+	// Deleted when the machine powers off or resets
+	GuestPropertyTransReset GuestPropertyFlag = "TRANSRESET"
+	// Read-only for the guest
+	GuestPropertyReadOnlyGuest GuestPropertyFlag = "RDONLYGUEST"
+	// Read-only for the host
+	GuestPropertyReadOnlyHost GuestPropertyFlag = "RDONLYHOST"
+	// Read-only for both
+	GuestPropertyReadOnly GuestPropertyFlag = "READONLY"
+)
+
+// GuestProperty is a name and value shared between a machine's guest and the
+// host.
+type GuestProperty struct {
+	Name      string
+	Value     string
+	Timestamp time.Time
+	Flags     []GuestPropertyFlag
+}
+
+// HasFlag reports whether the property has the given flag.
+func (p *GuestProperty) HasFlag(flag GuestPropertyFlag) bool {
+	for _, f := range p.Flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GuestPropertyChangedEvent is an event of type
+// VBoxEventTypeOnGuestPropertyChanged.
+type GuestPropertyChangedEvent struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (m *Machine) DeleteGuestProperty(name string) error {
+	request := vboxwebsrv.IMachinedeleteGuestProperty{This: m.managedObjectId, Name: name}
+
+	_, err := m.virtualbox.IMachinedeleteGuestProperty(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// EnumerateGuestProperties returns the guest properties whose names match
+// patterns, which are separated by "|" and may use the wildcards "*" and "?".
+// An empty pattern matches all properties.
+func (m *Machine) EnumerateGuestProperties(patterns string) ([]*GuestProperty, error) {
+	request := vboxwebsrv.IMachineenumerateGuestProperties{This: m.managedObjectId, Patterns: patterns}
+
+	response, err := m.virtualbox.IMachineenumerateGuestProperties(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	if len(response.Values) != len(response.Names) || len(response.Timestamps) != len(response.Names) || len(response.Flags) != len(response.Names) {
+		return nil, errors.New("guest property lists have different lengths")
+	}
+
+	properties := make([]*GuestProperty, len(response.Names))
+	for i, name := range response.Names {
+		properties[i] = &GuestProperty{
+			Name:      name,
+			Value:     response.Values[i],
+			Timestamp: time.Unix(0, response.Timestamps[i]),
+			Flags:     parseGuestPropertyFlags(response.Flags[i]),
+		}
+	}
+
+	return properties, nil
+}
+
+// GetGuestProperty returns a guest property, or nil if it is not set.
+func (m *Machine) GetGuestProperty(name string) (*GuestProperty, error) {
+	request := vboxwebsrv.IMachinegetGuestProperty{This: m.managedObjectId, Name: name}
+
+	response, err := m.virtualbox.IMachinegetGuestProperty(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	if response.Value == "" && response.Timestamp == 0 {
+		return nil, nil
+	}
+
+	return &GuestProperty{Name: name, Value: response.Value, Timestamp: time.Unix(0, response.Timestamp), Flags: parseGuestPropertyFlags(response.Flags)}, nil
+}
+
+// SetGuestProperty sets a guest property, replacing its flags. An empty value
+// deletes the property.
+func (m *Machine) SetGuestProperty(name, value string, flags []GuestPropertyFlag) error {
+	names := make([]string, len(flags))
+	for i, flag := range flags {
+		names[i] = string(flag)
+	}
+
+	request := vboxwebsrv.IMachinesetGuestProperty{This: m.managedObjectId, Property: name, Value: value, Flags: strings.Join(names, ",")}
+
+	_, err := m.virtualbox.IMachinesetGuestProperty(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// WaitForGuestProperty waits until a guest property whose name matches
+// pattern, in the syntax of EnumerateGuestProperties, satisfies predicate and
+// returns it. A nil predicate accepts any property that is set. Properties
+// that already exist are checked first; after that the predicate sees each
+// change, with an empty value for deleted properties and the time the change
+// was received as the timestamp. It returns ErrGuestTimeout if the context's
+// deadline passes first.
+func (m *Machine) WaitForGuestProperty(ctx context.Context, pattern string, predicate func(*GuestProperty) bool) (*GuestProperty, error) {
+	if predicate == nil {
+		predicate = func(p *GuestProperty) bool { return p.Value != "" }
+	}
+
+	machineId, err := m.GetId()
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := m.virtualbox.GetEventSource()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := source.CreateListener()
+	if err != nil {
+		return nil, err
+	}
+
+	// Listen before checking the existing properties so no change is missed
+	changed := vboxwebsrv.VBoxEventTypeOnGuestPropertyChanged
+	if err := source.RegisterListener(listener, []*vboxwebsrv.VBoxEventType{&changed}, false); err != nil {
+		return nil, err
+	}
+	defer source.UnregisterListener(listener)
+
+	properties, err := m.EnumerateGuestProperties(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, property := range properties {
+		if predicate(property) {
+			return property, nil
+		}
+	}
+
+	for {
+		event, err := source.GetEvent(listener, int32(waitTimeoutMS(ctx)))
+		if err != nil {
+			return nil, err
+		}
+
+		if event != nil {
+			property, err := m.guestPropertyFromEvent(event, machineId, pattern)
+			if processErr := source.EventProcessed(listener, event); err == nil {
+				err = processErr
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if property != nil && predicate(property) {
+				return property, nil
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil, contextError(ctx)
+		}
+	}
+}
+
+// guestPropertyFromEvent returns the property changed by event, or nil if the
+// event is for another machine or a property not matching pattern.
+func (m *Machine) guestPropertyFromEvent(event *Event, machineId, pattern string) (*GuestProperty, error) {
+	changed := &GuestPropertyChangedEvent{event.virtualbox, event.managedObjectId}
+
+	eventMachineId, err := changed.GetMachineId()
+	if err != nil {
+		return nil, err
+	}
+	if eventMachineId != machineId {
+		return nil, nil
+	}
+
+	name, err := changed.GetName()
+	if err != nil {
+		return nil, err
+	}
+	if !matchGuestPropertyPattern(pattern, name) {
+		return nil, nil
+	}
+
+	value, err := changed.GetValue()
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := changed.GetFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GuestProperty{Name: name, Value: value, Timestamp: time.Now(), Flags: parseGuestPropertyFlags(flags)}, nil
+}
+
+func (e *GuestPropertyChangedEvent) GetFlags() (string, error) {
+	request := vboxwebsrv.IGuestPropertyChangedEventgetFlags{This: e.managedObjectId}
+
+	response, err := e.virtualbox.IGuestPropertyChangedEventgetFlags(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (e *GuestPropertyChangedEvent) GetMachineId() (string, error) {
+	request := vboxwebsrv.IMachineEventgetMachineId{This: e.managedObjectId}
+
+	response, err := e.virtualbox.IMachineEventgetMachineId(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (e *GuestPropertyChangedEvent) GetName() (string, error) {
+	request := vboxwebsrv.IGuestPropertyChangedEventgetName{This: e.managedObjectId}
+
+	response, err := e.virtualbox.IGuestPropertyChangedEventgetName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (e *GuestPropertyChangedEvent) GetValue() (string, error) {
+	request := vboxwebsrv.IGuestPropertyChangedEventgetValue{This: e.managedObjectId}
+
+	response, err := e.virtualbox.IGuestPropertyChangedEventgetValue(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// parseGuestPropertyFlags splits a comma-separated list of flags.
+func parseGuestPropertyFlags(flags string) []GuestPropertyFlag {
+	var parsed []GuestPropertyFlag
+	for _, flag := range strings.Split(flags, ",") {
+		flag = strings.ToUpper(strings.TrimSpace(flag))
+		if flag != "" && flag != "NONE" {
+			parsed = append(parsed, GuestPropertyFlag(flag))
+		}
+	}
+
+	return parsed
+}
+
+// matchGuestPropertyPattern matches name the way VirtualBox matches guest
+// property patterns: alternatives are separated by "|", "*" matches any string
+// including "/" and "?" any single character. An empty pattern matches all
+// names.
+func matchGuestPropertyPattern(patterns, name string) bool {
+	if patterns == "" {
+		return true
+	}
+
+	for _, pattern := range strings.Split(patterns, "|") {
+		if matchSimplePattern(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchSimplePattern(pattern, name string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			for i := 0; i <= len(name); i++ {
+				if matchSimplePattern(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if name == "" {
+				return false
+			}
+			_, size := utf8.DecodeRuneInString(name)
+			name = name[size:]
+		default:
+			if name == "" || name[0] != pattern[0] {
+				return false
+			}
+			name = name[1:]
+		}
+		pattern = pattern[1:]
+	}
+
+	return name == ""
+}
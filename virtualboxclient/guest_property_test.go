@@ -0,0 +1,69 @@
+package virtualboxclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchGuestPropertyPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"", "/VirtualBox/GuestInfo/OS/Product", true},
+		{"", "", true},
+
+		{"/VirtualBox/GuestInfo/OS/Product", "/VirtualBox/GuestInfo/OS/Product", true},
+		{"/VirtualBox/GuestInfo/OS/Product", "/VirtualBox/GuestInfo/OS/Release", false},
+		{"/VirtualBox/GuestInfo/OS", "/VirtualBox/GuestInfo/OS/Product", false},
+
+		// "*" matches across "/"
+		{"/VirtualBox/*", "/VirtualBox/GuestInfo/Net/0/V4/IP", true},
+		{"*/V4/IP", "/VirtualBox/GuestInfo/Net/0/V4/IP", true},
+		{"/VirtualBox/GuestInfo/Net/*/V4/IP", "/VirtualBox/GuestInfo/Net/1/V4/IP", true},
+		{"/VirtualBox/GuestInfo/Net/*/V4/IP", "/VirtualBox/GuestInfo/Net/1/V6/IP", false},
+		{"*", "", true},
+		{"a*", "a", true},
+		{"*b*", "abc", true},
+		{"*b*", "ac", false},
+
+		// "?" matches exactly one character, which may be multi-byte
+		{"/Net/?/IP", "/Net/0/IP", true},
+		{"/Net/?/IP", "/Net/10/IP", false},
+		{"/Net/?/IP", "/Net//IP", false},
+		{"/Name/?", "/Name/é", true},
+		{"?", "", false},
+
+		// Alternatives separated by "|"
+		{"/A|/B", "/B", true},
+		{"/A|/B", "/C", false},
+		{"/A|*/IP", "/Net/0/IP", true},
+		{"/A|", "", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGuestPropertyPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGuestPropertyPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseGuestPropertyFlags(t *testing.T) {
+	tests := []struct {
+		flags string
+		want  []GuestPropertyFlag
+	}{
+		{"", nil},
+		{"NONE", nil},
+		{"TRANSIENT", []GuestPropertyFlag{GuestPropertyTransient}},
+		{"transient, rdonlyguest", []GuestPropertyFlag{GuestPropertyTransient, GuestPropertyReadOnlyGuest}},
+		{"TRANSRESET,,READONLY", []GuestPropertyFlag{GuestPropertyTransReset, GuestPropertyReadOnly}},
+	}
+
+	for _, tt := range tests {
+		if got := parseGuestPropertyFlags(tt.flags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseGuestPropertyFlags(%q) = %v, want %v", tt.flags, got, tt.want)
+		}
+	}
+}
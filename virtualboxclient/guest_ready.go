@@ -0,0 +1,144 @@
+package virtualboxclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// How often WaitForGuestReady checks the guest
+const guestReadyPollInterval = time.Second
+
+// GuestReadyOptions are conditions WaitForGuestReady waits for in addition to
+// the Guest Additions run level.
+type GuestReadyOptions struct {
+	// Facilities that must be active, e.g. AdditionsFacilityTypeVBoxService
+	// or AdditionsFacilityTypeGraphics
+	Facilities []vboxwebsrv.AdditionsFacilityType
+
+	// Guest property that must have a value, e.g.
+	// "/VirtualBox/GuestInfo/Net/0/V4/IP"
+	Property string
+}
+
+// GuestReadyInfo describes a guest that is ready.
+type GuestReadyInfo struct {
+	OSTypeId         string
+	AdditionsVersion string
+
+	// Value of GuestReadyOptions.Property, if given
+	PropertyValue string
+}
+
+// WaitForGuestReady waits until the machine is running and its Guest Additions
+// have reached at least the given run level and met the conditions in opts,
+// which may be nil. A machine that is not running yet, for example just after
+// it was launched, is waited for. It fails if the machine stops running after
+// it was seen running or gets stuck, and returns ErrGuestTimeout if the
+// context's deadline passes first. Errors talking to the guest while it boots
+// are retried; the last one is included in the timeout error.
+func (m *Machine) WaitForGuestReady(ctx context.Context, level vboxwebsrv.AdditionsRunLevelType, opts *GuestReadyOptions) (*GuestReadyInfo, error) {
+	if opts == nil {
+		opts = &GuestReadyOptions{}
+	}
+
+	ticker := time.NewTicker(guestReadyPollInterval)
+	defer ticker.Stop()
+
+	// The guest is kept across polls and only obtained again after an error
+	var guest *Guest
+	defer func() {
+		if guest != nil {
+			guest.Release()
+		}
+	}()
+
+	seenOnline := false
+	var lastErr error
+	for {
+		state, err := m.GetState()
+		if err != nil {
+			return nil, err
+		}
+
+		if state != nil {
+			switch {
+			case *state == vboxwebsrv.MachineStateStuck:
+				return nil, fmt.Errorf("machine is stuck")
+			case machineOnlineStates[*state]:
+				seenOnline = true
+			case seenOnline:
+				return nil, fmt.Errorf("machine stopped running (state %s)", *state)
+			}
+		}
+
+		if state != nil && *state == vboxwebsrv.MachineStateRunning {
+			if guest == nil {
+				if guest, err = m.GetGuest(); err != nil {
+					lastErr = err
+				}
+			}
+
+			if guest != nil {
+				info, err := m.checkGuestReady(guest, level, opts)
+				if err != nil {
+					lastErr = err
+					guest.Release()
+					guest = nil
+				} else if info != nil {
+					return info, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w (last error: %v)", contextError(ctx), lastErr)
+			}
+
+			return nil, contextError(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkGuestReady returns nil info if the guest is not ready yet.
+func (m *Machine) checkGuestReady(guest *Guest, level vboxwebsrv.AdditionsRunLevelType, opts *GuestReadyOptions) (*GuestReadyInfo, error) {
+	ok, err := guest.GetAdditionsStatus(level)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	for _, facility := range opts.Facilities {
+		status, _, err := guest.GetFacilityStatus(facility)
+		if err != nil {
+			return nil, err
+		}
+		if status == nil || *status != vboxwebsrv.AdditionsFacilityStatusActive {
+			return nil, nil
+		}
+	}
+
+	info := &GuestReadyInfo{}
+
+	if opts.Property != "" {
+		if info.PropertyValue, err = m.GetGuestPropertyValue(opts.Property); err != nil {
+			return nil, err
+		}
+		if info.PropertyValue == "" {
+			return nil, nil
+		}
+	}
+
+	if info.OSTypeId, err = guest.GetOSTypeId(); err != nil {
+		return nil, err
+	}
+	if info.AdditionsVersion, err = guest.GetAdditionsVersion(); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
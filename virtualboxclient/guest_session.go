@@ -0,0 +1,123 @@
+package virtualboxclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type GuestSession struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+// Close ends the session, terminating any processes still running in it.
+func (gs *GuestSession) Close() error {
+	request := vboxwebsrv.IGuestSessionclose{This: gs.managedObjectId}
+
+	_, err := gs.virtualbox.IGuestSessionclose(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// CopyFrom copies a file from the guest to the host the VirtualBox server runs
+// on.
+func (gs *GuestSession) CopyFrom(source, dest string, flags []*vboxwebsrv.CopyFileFlag) (*Progress, error) {
+	request := vboxwebsrv.IGuestSessioncopyFrom{This: gs.managedObjectId, Source: source, Dest: dest, Flags: flags}
+
+	response, err := gs.virtualbox.IGuestSessioncopyFrom(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{gs.virtualbox, response.Returnval}, nil
+}
+
+// CopyTo copies a file from the host the VirtualBox server runs on to the
+// guest.
+func (gs *GuestSession) CopyTo(source, dest string, flags []*vboxwebsrv.CopyFileFlag) (*Progress, error) {
+	request := vboxwebsrv.IGuestSessioncopyTo{This: gs.managedObjectId, Source: source, Dest: dest, Flags: flags}
+
+	response, err := gs.virtualbox.IGuestSessioncopyTo(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{gs.virtualbox, response.Returnval}, nil
+}
+
+func (gs *GuestSession) GetStatus() (*vboxwebsrv.GuestSessionStatus, error) {
+	request := vboxwebsrv.IGuestSessiongetStatus{This: gs.managedObjectId}
+
+	response, err := gs.virtualbox.IGuestSessiongetStatus(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// ProcessCreate starts command in the guest. The environment is a list of
+// "NAME=value" entries added to the session's environment. A timeoutMS of zero
+// lets the process run indefinitely.
+func (gs *GuestSession) ProcessCreate(command string, args []string, env []string, flags []*vboxwebsrv.ProcessCreateFlag, timeoutMS uint32) (*GuestProcess, error) {
+	request := vboxwebsrv.IGuestSessionprocessCreate{This: gs.managedObjectId, Command: command, Arguments: args, Environment: env, Flags: flags, TimeoutMS: timeoutMS}
+
+	response, err := gs.virtualbox.IGuestSessionprocessCreate(&request)
+	if err != nil {
+		return nil, classifyGuestError(err)
+	}
+
+	return &GuestProcess{gs.virtualbox, response.Returnval}, nil
+}
+
+func (gs *GuestSession) WaitForArray(waitFor []*vboxwebsrv.GuestSessionWaitForFlag, timeoutMS uint32) (*vboxwebsrv.GuestSessionWaitResult, error) {
+	request := vboxwebsrv.IGuestSessionwaitForArray{This: gs.managedObjectId, WaitFor: waitFor, TimeoutMS: timeoutMS}
+
+	response, err := gs.virtualbox.IGuestSessionwaitForArray(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// waitForStart waits until the session has started. VirtualBox 4.3 does not
+// say why a session failed to start, but with the additions running the
+// reason is almost always that the guest refused the logon, so a failed
+// session is reported as ErrGuestAuthentication.
+func (gs *GuestSession) waitForStart(ctx context.Context) error {
+	start := vboxwebsrv.GuestSessionWaitForFlagStart
+
+	for {
+		if _, err := gs.WaitForArray([]*vboxwebsrv.GuestSessionWaitForFlag{&start}, waitTimeoutMS(ctx)); err != nil {
+			return classifyGuestError(err)
+		}
+
+		status, err := gs.GetStatus()
+		if err != nil {
+			return err
+		}
+
+		if status != nil {
+			switch *status {
+			case vboxwebsrv.GuestSessionStatusStarted:
+				return nil
+			case vboxwebsrv.GuestSessionStatusError:
+				return ErrGuestAuthentication
+			case vboxwebsrv.GuestSessionStatusTimedOutKilled, vboxwebsrv.GuestSessionStatusTimedOutAbnormally:
+				return ErrGuestTimeout
+			case vboxwebsrv.GuestSessionStatusTerminating, vboxwebsrv.GuestSessionStatusTerminated, vboxwebsrv.GuestSessionStatusDown:
+				return fmt.Errorf("guest session ended while starting: %s", *status)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// SymlinkCreate creates the symbolic link source pointing to target.
+func (gs *GuestSession) SymlinkCreate(source, target string, symlinkType vboxwebsrv.SymlinkType) error {
+	request := vboxwebsrv.IGuestSessionsymlinkCreate{This: gs.managedObjectId, Source: source, Target: target, Type_: &symlinkType}
+
+	_, err := gs.virtualbox.IGuestSessionsymlinkCreate(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (gs *GuestSession) SymlinkExists(symlink string) (bool, error) {
+	request := vboxwebsrv.IGuestSessionsymlinkExists{This: gs.managedObjectId, Symlink: symlink}
+
+	response, err := gs.virtualbox.IGuestSessionsymlinkExists(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// SymlinkRead returns the target of a symbolic link.
+func (gs *GuestSession) SymlinkRead(symlink string, flags []*vboxwebsrv.SymlinkReadFlag) (string, error) {
+	request := vboxwebsrv.IGuestSessionsymlinkRead{This: gs.managedObjectId, Symlink: symlink, Flags: flags}
+
+	response, err := gs.virtualbox.IGuestSessionsymlinkRead(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (gs *GuestSession) SymlinkRemoveDirectory(path string) error {
+	request := vboxwebsrv.IGuestSessionsymlinkRemoveDirectory{This: gs.managedObjectId, Path: path}
+
+	_, err := gs.virtualbox.IGuestSessionsymlinkRemoveDirectory(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (gs *GuestSession) SymlinkRemoveFile(file string) error {
+	request := vboxwebsrv.IGuestSessionsymlinkRemoveFile{This: gs.managedObjectId, File: file}
+
+	_, err := gs.virtualbox.IGuestSessionsymlinkRemoveFile(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
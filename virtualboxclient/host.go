@@ -0,0 +1,444 @@
+package virtualboxclient
+
+import (
+	"time"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// CPUIDLeaf holds the registers returned by the CPUID instruction.
+type CPUIDLeaf struct {
+	Leaf    uint32
+	SubLeaf uint32
+
+	EAX uint32
+	EBX uint32
+	ECX uint32
+	EDX uint32
+}
+
+// HostInfo is a snapshot of the host's resources and capabilities, as returned
+// by Host.GetInfo. Memory sizes are in megabytes and the processor speed in
+// MHz; per-processor values are those of the first CPU.
+type HostInfo struct {
+	OperatingSystem string
+	OSVersion       string
+	UTCTime         time.Time
+
+	ProcessorCount           uint32
+	ProcessorOnlineCount     uint32
+	ProcessorCoreCount       uint32
+	ProcessorOnlineCoreCount uint32
+	ProcessorSpeed           uint32
+	ProcessorDescription     string
+	ProcessorFeatures        map[vboxwebsrv.ProcessorFeature]bool
+
+	// The standard and extended CPUID leaves, sub-leaf 0 only
+	CPUIDLeaves []CPUIDLeaf
+
+	MemorySize      uint32
+	MemoryAvailable uint32
+
+	Acceleration3DAvailable bool
+
+	DomainName    string
+	NameServers   []string
+	SearchStrings []string
+}
+
+var processorFeatures = []vboxwebsrv.ProcessorFeature{
+	vboxwebsrv.ProcessorFeatureHWVirtEx,
+	vboxwebsrv.ProcessorFeaturePAE,
+	vboxwebsrv.ProcessorFeatureLongMode,
+	vboxwebsrv.ProcessorFeatureNestedPaging,
+}
+
+// Upper bound on the number of leaves GetCPUIDLeaves reads from each range,
+// in case the reported maximum leaf is bogus
+const maxCPUIDLeaves = 64
+
+type Host struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+// CreateHostOnlyNetworkInterface creates a new host-only interface. The
+// interface is usable once the returned progress completes.
+func (h *Host) CreateHostOnlyNetworkInterface() (*HostNetworkInterface, *Progress, error) {
+	request := vboxwebsrv.IHostcreateHostOnlyNetworkInterface{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostcreateHostOnlyNetworkInterface(&request)
+	if err != nil {
+		return nil, nil, err // TODO: Wrap the error
+	}
+
+	return &HostNetworkInterface{h.virtualbox, response.HostInterface}, &Progress{h.virtualbox, response.Returnval}, nil
+}
+
+func (h *Host) FindHostNetworkInterfaceById(id string) (*HostNetworkInterface, error) {
+	request := vboxwebsrv.IHostfindHostNetworkInterfaceById{This: h.managedObjectId, Id: id}
+
+	response, err := h.virtualbox.IHostfindHostNetworkInterfaceById(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &HostNetworkInterface{h.virtualbox, response.Returnval}, nil
+}
+
+func (h *Host) FindHostNetworkInterfaceByName(name string) (*HostNetworkInterface, error) {
+	request := vboxwebsrv.IHostfindHostNetworkInterfaceByName{This: h.managedObjectId, Name: name}
+
+	response, err := h.virtualbox.IHostfindHostNetworkInterfaceByName(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &HostNetworkInterface{h.virtualbox, response.Returnval}, nil
+}
+
+func (h *Host) FindHostNetworkInterfacesOfType(interfaceType vboxwebsrv.HostNetworkInterfaceType) ([]*HostNetworkInterface, error) {
+	request := vboxwebsrv.IHostfindHostNetworkInterfacesOfType{This: h.managedObjectId, Type_: &interfaceType}
+
+	response, err := h.virtualbox.IHostfindHostNetworkInterfacesOfType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	interfaces := make([]*HostNetworkInterface, len(response.Returnval))
+	for i, oid := range response.Returnval {
+		interfaces[i] = &HostNetworkInterface{h.virtualbox, oid}
+	}
+
+	return interfaces, nil
+}
+
+// GenerateMACAddress returns a new random MAC address with the VirtualBox OUI,
+// in the form accepted by NetworkAdapter.SetMACAddress.
+func (h *Host) GenerateMACAddress() (string, error) {
+	request := vboxwebsrv.IHostgenerateMACAddress{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgenerateMACAddress(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetAcceleration3DAvailable() (bool, error) {
+	request := vboxwebsrv.IHostgetAcceleration3DAvailable{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetAcceleration3DAvailable(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetCPUIDLeaves returns sub-leaf 0 of every standard and extended CPUID leaf
+// of the given CPU, up to the maximum leaves it reports.
+func (h *Host) GetCPUIDLeaves(cpuId uint32) ([]CPUIDLeaf, error) {
+	var leaves []CPUIDLeaf
+	for _, base := range []uint32{0x0, 0x80000000} {
+		first, err := h.GetProcessorCPUIDLeaf(cpuId, base, 0)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, *first)
+
+		// EAX of the first leaf in each range is the highest leaf in it
+		for leaf := base + 1; leaf <= first.EAX && leaf-base < maxCPUIDLeaves; leaf++ {
+			registers, err := h.GetProcessorCPUIDLeaf(cpuId, leaf, 0)
+			if err != nil {
+				return nil, err
+			}
+			leaves = append(leaves, *registers)
+		}
+	}
+
+	return leaves, nil
+}
+
+func (h *Host) GetDomainName() (string, error) {
+	request := vboxwebsrv.IHostgetDomainName{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetDomainName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetInfo() (*HostInfo, error) {
+	var info HostInfo
+	var err error
+
+	for _, field := range []struct {
+		value *string
+		get   func() (string, error)
+	}{
+		{&info.OperatingSystem, h.GetOperatingSystem},
+		{&info.OSVersion, h.GetOSVersion},
+		{&info.DomainName, h.GetDomainName},
+	} {
+		if *field.value, err = field.get(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range []struct {
+		value *uint32
+		get   func() (uint32, error)
+	}{
+		{&info.ProcessorCount, h.GetProcessorCount},
+		{&info.ProcessorOnlineCount, h.GetProcessorOnlineCount},
+		{&info.ProcessorCoreCount, h.GetProcessorCoreCount},
+		{&info.ProcessorOnlineCoreCount, h.GetProcessorOnlineCoreCount},
+		{&info.MemorySize, h.GetMemorySize},
+		{&info.MemoryAvailable, h.GetMemoryAvailable},
+	} {
+		if *field.value, err = field.get(); err != nil {
+			return nil, err
+		}
+	}
+
+	if info.UTCTime, err = h.GetUTCTime(); err != nil {
+		return nil, err
+	}
+
+	if info.ProcessorSpeed, err = h.GetProcessorSpeed(0); err != nil {
+		return nil, err
+	}
+
+	if info.ProcessorDescription, err = h.GetProcessorDescription(0); err != nil {
+		return nil, err
+	}
+
+	info.ProcessorFeatures = make(map[vboxwebsrv.ProcessorFeature]bool)
+	for _, feature := range processorFeatures {
+		if info.ProcessorFeatures[feature], err = h.GetProcessorFeature(feature); err != nil {
+			return nil, err
+		}
+	}
+
+	if info.CPUIDLeaves, err = h.GetCPUIDLeaves(0); err != nil {
+		return nil, err
+	}
+
+	if info.Acceleration3DAvailable, err = h.GetAcceleration3DAvailable(); err != nil {
+		return nil, err
+	}
+
+	if info.NameServers, err = h.GetNameServers(); err != nil {
+		return nil, err
+	}
+
+	if info.SearchStrings, err = h.GetSearchStrings(); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetMemoryAvailable returns the free host memory in megabytes.
+func (h *Host) GetMemoryAvailable() (uint32, error) {
+	request := vboxwebsrv.IHostgetMemoryAvailable{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetMemoryAvailable(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetMemorySize returns the total host memory in megabytes.
+func (h *Host) GetMemorySize() (uint32, error) {
+	request := vboxwebsrv.IHostgetMemorySize{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetMemorySize(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetNameServers() ([]string, error) {
+	request := vboxwebsrv.IHostgetNameServers{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetNameServers(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetNetworkInterfaces() ([]*HostNetworkInterface, error) {
+	request := vboxwebsrv.IHostgetNetworkInterfaces{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetNetworkInterfaces(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	interfaces := make([]*HostNetworkInterface, len(response.Returnval))
+	for i, oid := range response.Returnval {
+		interfaces[i] = &HostNetworkInterface{h.virtualbox, oid}
+	}
+
+	return interfaces, nil
+}
+
+func (h *Host) GetOSVersion() (string, error) {
+	request := vboxwebsrv.IHostgetOSVersion{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetOSVersion(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetOperatingSystem() (string, error) {
+	request := vboxwebsrv.IHostgetOperatingSystem{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetOperatingSystem(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetProcessorCPUIDLeaf returns the registers of the given CPUID leaf and
+// sub-leaf as reported by the given CPU.
+func (h *Host) GetProcessorCPUIDLeaf(cpuId, leaf, subLeaf uint32) (*CPUIDLeaf, error) {
+	request := vboxwebsrv.IHostgetProcessorCPUIDLeaf{This: h.managedObjectId, CpuId: cpuId, Leaf: leaf, SubLeaf: subLeaf}
+
+	response, err := h.virtualbox.IHostgetProcessorCPUIDLeaf(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &CPUIDLeaf{Leaf: leaf, SubLeaf: subLeaf, EAX: response.ValEax, EBX: response.ValEbx, ECX: response.ValEcx, EDX: response.ValEdx}, nil
+}
+
+func (h *Host) GetProcessorCoreCount() (uint32, error) {
+	request := vboxwebsrv.IHostgetProcessorCoreCount{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetProcessorCoreCount(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetProcessorCount() (uint32, error) {
+	request := vboxwebsrv.IHostgetProcessorCount{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetProcessorCount(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetProcessorDescription(cpuId uint32) (string, error) {
+	request := vboxwebsrv.IHostgetProcessorDescription{This: h.managedObjectId, CpuId: cpuId}
+
+	response, err := h.virtualbox.IHostgetProcessorDescription(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetProcessorFeature(feature vboxwebsrv.ProcessorFeature) (bool, error) {
+	request := vboxwebsrv.IHostgetProcessorFeature{This: h.managedObjectId, Feature: &feature}
+
+	response, err := h.virtualbox.IHostgetProcessorFeature(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetProcessorOnlineCoreCount() (uint32, error) {
+	request := vboxwebsrv.IHostgetProcessorOnlineCoreCount{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetProcessorOnlineCoreCount(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetProcessorOnlineCount() (uint32, error) {
+	request := vboxwebsrv.IHostgetProcessorOnlineCount{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetProcessorOnlineCount(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetProcessorSpeed returns the speed of the given CPU in MHz.
+func (h *Host) GetProcessorSpeed(cpuId uint32) (uint32, error) {
+	request := vboxwebsrv.IHostgetProcessorSpeed{This: h.managedObjectId, CpuId: cpuId}
+
+	response, err := h.virtualbox.IHostgetProcessorSpeed(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetSearchStrings() ([]string, error) {
+	request := vboxwebsrv.IHostgetSearchStrings{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetSearchStrings(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (h *Host) GetUTCTime() (time.Time, error) {
+	request := vboxwebsrv.IHostgetUTCTime{This: h.managedObjectId}
+
+	response, err := h.virtualbox.IHostgetUTCTime(&request)
+	if err != nil {
+		return time.Time{}, err // TODO: Wrap the error
+	}
+
+	// Milliseconds since the epoch
+	return time.Unix(0, response.Returnval*int64(time.Millisecond)).UTC(), nil
+}
+
+// RemoveHostOnlyNetworkInterface removes the host-only interface with the
+// given ID.
+func (h *Host) RemoveHostOnlyNetworkInterface(id string) (*Progress, error) {
+	request := vboxwebsrv.IHostremoveHostOnlyNetworkInterface{This: h.managedObjectId, Id: id}
+
+	response, err := h.virtualbox.IHostremoveHostOnlyNetworkInterface(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{h.virtualbox, response.Returnval}, nil
+}
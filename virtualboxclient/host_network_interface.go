@@ -0,0 +1,297 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// HostNetworkInterfaceInfo is a snapshot of a host network interface's
+// properties, as returned by HostNetworkInterface.GetInfo.
+type HostNetworkInterfaceInfo struct {
+	Name            string
+	ShortName       string
+	Id              string
+	NetworkName     string
+	InterfaceType   vboxwebsrv.HostNetworkInterfaceType
+	Status          vboxwebsrv.HostNetworkInterfaceStatus
+	MediumType      vboxwebsrv.HostNetworkInterfaceMediumType
+	HardwareAddress string
+	DHCPEnabled     bool
+
+	IPAddress   string
+	NetworkMask string
+
+	IPV6Supported               bool
+	IPV6Address                 string
+	IPV6NetworkMaskPrefixLength uint32
+}
+
+type HostNetworkInterface struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (hni *HostNetworkInterface) DHCPRediscover() error {
+	request := vboxwebsrv.IHostNetworkInterfaceDHCPRediscover{This: hni.managedObjectId}
+
+	_, err := hni.virtualbox.IHostNetworkInterfaceDHCPRediscover(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (hni *HostNetworkInterface) EnableDynamicIPConfig() error {
+	request := vboxwebsrv.IHostNetworkInterfaceenableDynamicIPConfig{This: hni.managedObjectId}
+
+	_, err := hni.virtualbox.IHostNetworkInterfaceenableDynamicIPConfig(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (hni *HostNetworkInterface) EnableStaticIPConfig(ipAddress, networkMask string) error {
+	request := vboxwebsrv.IHostNetworkInterfaceenableStaticIPConfig{This: hni.managedObjectId, IPAddress: ipAddress, NetworkMask: networkMask}
+
+	_, err := hni.virtualbox.IHostNetworkInterfaceenableStaticIPConfig(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (hni *HostNetworkInterface) EnableStaticIPConfigV6(ipv6Address string, prefixLength uint32) error {
+	request := vboxwebsrv.IHostNetworkInterfaceenableStaticIPConfigV6{This: hni.managedObjectId, IPV6Address: ipv6Address, IPV6NetworkMaskPrefixLength: prefixLength}
+
+	_, err := hni.virtualbox.IHostNetworkInterfaceenableStaticIPConfigV6(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (hni *HostNetworkInterface) GetDHCPEnabled() (bool, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetDHCPEnabled{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetDHCPEnabled(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetHardwareAddress() (string, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetHardwareAddress{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetHardwareAddress(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetIPAddress() (string, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetIPAddress{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetIPAddress(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetIPV6Address() (string, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetIPV6Address{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetIPV6Address(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetIPV6NetworkMaskPrefixLength() (uint32, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetIPV6NetworkMaskPrefixLength{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetIPV6NetworkMaskPrefixLength(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetIPV6Supported() (bool, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetIPV6Supported{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetIPV6Supported(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetId() (string, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetId{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetId(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetInfo() (*HostNetworkInterfaceInfo, error) {
+	var info HostNetworkInterfaceInfo
+	var err error
+
+	for _, field := range []struct {
+		value *string
+		get   func() (string, error)
+	}{
+		{&info.Name, hni.GetName},
+		{&info.ShortName, hni.GetShortName},
+		{&info.Id, hni.GetId},
+		{&info.NetworkName, hni.GetNetworkName},
+		{&info.HardwareAddress, hni.GetHardwareAddress},
+		{&info.IPAddress, hni.GetIPAddress},
+		{&info.NetworkMask, hni.GetNetworkMask},
+	} {
+		if *field.value, err = field.get(); err != nil {
+			return nil, err
+		}
+	}
+
+	interfaceType, err := hni.GetInterfaceType()
+	if err != nil {
+		return nil, err
+	}
+	if interfaceType != nil {
+		info.InterfaceType = *interfaceType
+	}
+
+	status, err := hni.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	if status != nil {
+		info.Status = *status
+	}
+
+	mediumType, err := hni.GetMediumType()
+	if err != nil {
+		return nil, err
+	}
+	if mediumType != nil {
+		info.MediumType = *mediumType
+	}
+
+	if info.DHCPEnabled, err = hni.GetDHCPEnabled(); err != nil {
+		return nil, err
+	}
+
+	if info.IPV6Supported, err = hni.GetIPV6Supported(); err != nil {
+		return nil, err
+	}
+
+	if info.IPV6Supported {
+		if info.IPV6Address, err = hni.GetIPV6Address(); err != nil {
+			return nil, err
+		}
+
+		if info.IPV6NetworkMaskPrefixLength, err = hni.GetIPV6NetworkMaskPrefixLength(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &info, nil
+}
+
+func (hni *HostNetworkInterface) GetInterfaceType() (*vboxwebsrv.HostNetworkInterfaceType, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetInterfaceType{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetInterfaceType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetMediumType() (*vboxwebsrv.HostNetworkInterfaceMediumType, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetMediumType{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetMediumType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetName() (string, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetName{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetNetworkMask() (string, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetNetworkMask{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetNetworkMask(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetNetworkName returns the name of the internal network the interface is
+// connected to, e.g. "HostInterfaceNetworking-vboxnet0".
+func (hni *HostNetworkInterface) GetNetworkName() (string, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetNetworkName{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetNetworkName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetShortName() (string, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetShortName{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetShortName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (hni *HostNetworkInterface) GetStatus() (*vboxwebsrv.HostNetworkInterfaceStatus, error) {
+	request := vboxwebsrv.IHostNetworkInterfacegetStatus{This: hni.managedObjectId}
+
+	response, err := hni.virtualbox.IHostNetworkInterfacegetStatus(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
@@ -0,0 +1,79 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ISCSITarget describes the remote storage backing an iSCSI medium. Formatting
+// it with fmt never prints the secrets.
+type ISCSITarget struct {
+	// Host name or IP address, optionally followed by ":port"
+	TargetAddress string
+	TargetName    string
+	LUN           uint64
+
+	InitiatorName     string
+	InitiatorUsername string
+	InitiatorSecret   string
+	TargetUsername    string
+	TargetSecret      string
+}
+
+func (t ISCSITarget) String() string {
+	return fmt.Sprintf("iSCSI %s %s LUN %d (initiator user %q, secret %s; target user %q, secret %s)",
+		t.TargetAddress, t.TargetName, t.LUN,
+		t.InitiatorUsername, redactSecret(t.InitiatorSecret),
+		t.TargetUsername, redactSecret(t.TargetSecret))
+}
+
+func (t ISCSITarget) GoString() string {
+	return "virtualboxclient.ISCSITarget{" + t.String() + "}"
+}
+
+func redactSecret(secret string) string {
+	if secret == "" {
+		return "unset"
+	}
+
+	return "[REDACTED]"
+}
+
+func (t ISCSITarget) properties() map[string]string {
+	properties := map[string]string{
+		"TargetAddress": t.TargetAddress,
+		"TargetName":    t.TargetName,
+		"LUN":           strconv.FormatUint(t.LUN, 10),
+	}
+
+	for name, value := range map[string]string{
+		"InitiatorName":     t.InitiatorName,
+		"InitiatorUsername": t.InitiatorUsername,
+		"InitiatorSecret":   t.InitiatorSecret,
+		"TargetUsername":    t.TargetUsername,
+		"TargetSecret":      t.TargetSecret,
+	} {
+		if value != "" {
+			properties[name] = value
+		}
+	}
+
+	return properties
+}
+
+// CreateISCSIHardDisk registers a hard disk backed by the given iSCSI target.
+// No storage is created; the medium can be attached to a machine directly.
+func (vb *VirtualBox) CreateISCSIHardDisk(target ISCSITarget) (*Medium, error) {
+	location := fmt.Sprintf("%s|%s|%d", target.TargetAddress, target.TargetName, target.LUN)
+
+	medium, err := vb.CreateHardDisk("iSCSI", location)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := medium.SetProperties(target.properties()); err != nil {
+		return nil, err
+	}
+
+	return medium, nil
+}
@@ -0,0 +1,238 @@
+package virtualboxclient
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// MACAddressUse is a network adapter and the MAC address configured on it.
+type MACAddressUse struct {
+	Machine     *Machine
+	MachineName string
+	Slot        uint32
+	Enabled     bool
+	MACAddress  string
+}
+
+type MACAddressReport struct {
+	Uses []MACAddressUse
+
+	// Adapters sharing an address, keyed by the normalized address
+	Duplicates map[string][]MACAddressUse
+
+	Invalid []MACAddressUse
+}
+
+// MACAddressChange records an adapter whose address was regenerated.
+type MACAddressChange struct {
+	MACAddressUse
+	NewMACAddress string
+}
+
+// NormalizeMACAddress strips ":" and "-" separators and upper-cases the
+// address, giving the form VirtualBox uses.
+func NormalizeMACAddress(mac string) string {
+	mac = strings.Replace(mac, ":", "", -1)
+	mac = strings.Replace(mac, "-", "", -1)
+
+	return strings.ToUpper(mac)
+}
+
+// ValidateMACAddress checks that mac is a unicast address other than all
+// zeroes, as required for a network adapter.
+func ValidateMACAddress(mac string) error {
+	b, err := hex.DecodeString(NormalizeMACAddress(mac))
+	if err != nil || len(b) != 6 {
+		return fmt.Errorf("invalid MAC address %q", mac)
+	}
+
+	if b[0]&0x01 != 0 {
+		return fmt.Errorf("MAC address %q is a multicast address", mac)
+	}
+
+	if b[0]|b[1]|b[2]|b[3]|b[4]|b[5] == 0 {
+		return fmt.Errorf("MAC address %q is all zeroes", mac)
+	}
+
+	return nil
+}
+
+// ScanMACAddresses collects the MAC addresses of every adapter, enabled or
+// not, of every accessible machine and reports the ones that are shared or
+// invalid.
+func (vb *VirtualBox) ScanMACAddresses() (*MACAddressReport, error) {
+	machines, err := vb.GetMachines()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MACAddressReport{Duplicates: make(map[string][]MACAddressUse)}
+	byAddress := make(map[string][]MACAddressUse)
+	for _, machine := range machines {
+		accessible, err := machine.GetAccessible()
+		if err != nil {
+			return nil, err
+		}
+		if !accessible {
+			continue
+		}
+
+		name, err := machine.GetName()
+		if err != nil {
+			return nil, err
+		}
+
+		adapters, err := machine.GetNetworkAdapters()
+		if err != nil {
+			return nil, err
+		}
+
+		for slot, adapter := range adapters {
+			use := MACAddressUse{Machine: machine, MachineName: name, Slot: uint32(slot)}
+
+			if use.Enabled, err = adapter.GetEnabled(); err != nil {
+				return nil, err
+			}
+
+			if use.MACAddress, err = adapter.GetMACAddress(); err != nil {
+				return nil, err
+			}
+
+			report.Uses = append(report.Uses, use)
+
+			if ValidateMACAddress(use.MACAddress) != nil {
+				report.Invalid = append(report.Invalid, use)
+				continue
+			}
+
+			mac := NormalizeMACAddress(use.MACAddress)
+			byAddress[mac] = append(byAddress[mac], use)
+		}
+	}
+
+	for mac, uses := range byAddress {
+		if len(uses) > 1 {
+			report.Duplicates[mac] = uses
+		}
+	}
+
+	return report, nil
+}
+
+// MACAddressGenerator generates MAC addresses not in use by any adapter it
+// has seen.
+type MACAddressGenerator struct {
+	VirtualBox *VirtualBox
+
+	// First three octets of generated addresses, e.g. "080027". Empty means
+	// addresses are generated by the host with the VirtualBox OUI.
+	OUI string
+
+	used map[string]bool
+}
+
+// markUsed records that mac must not be generated.
+func (g *MACAddressGenerator) markUsed(mac string) {
+	if g.used == nil {
+		g.used = make(map[string]bool)
+	}
+
+	g.used[NormalizeMACAddress(mac)] = true
+}
+
+// Generate returns an address that is valid and has not been generated or
+// reported before.
+func (g *MACAddressGenerator) Generate() (string, error) {
+	oui := NormalizeMACAddress(g.OUI)
+	if oui != "" {
+		if err := ValidateMACAddress(oui + "000001"); err != nil {
+			return "", fmt.Errorf("invalid OUI %q", g.OUI)
+		}
+	}
+
+	for attempt := 0; attempt < 100; attempt++ {
+		var mac string
+		if oui == "" {
+			host, err := g.VirtualBox.GetHost()
+			if err != nil {
+				return "", err
+			}
+
+			if mac, err = host.GenerateMACAddress(); err != nil {
+				return "", err
+			}
+		} else {
+			mac = fmt.Sprintf("%s%06X", oui, rand.Intn(1<<24))
+		}
+
+		mac = NormalizeMACAddress(mac)
+		if ValidateMACAddress(mac) == nil && !g.used[mac] {
+			g.markUsed(mac)
+			return mac, nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to generate an unused MAC address")
+}
+
+// Regenerate assigns a newly generated address to the adapter of use, locking
+// the machine for writing. This fails if the machine is running.
+func (g *MACAddressGenerator) Regenerate(use MACAddressUse) (string, error) {
+	mac, err := g.Generate()
+	if err != nil {
+		return "", err
+	}
+
+	err = use.Machine.WithLock(vboxwebsrv.LockTypeWrite, func(mutable *Machine) error {
+		adapter, err := mutable.GetNetworkAdapter(use.Slot)
+		if err != nil {
+			return err
+		}
+
+		return adapter.SetMACAddress(mac)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return mac, nil
+}
+
+// Fix regenerates the addresses of all invalid adapters in report, and of all
+// but the first adapter sharing each duplicate address. The changes made
+// before an error are returned along with it.
+func (g *MACAddressGenerator) Fix(report *MACAddressReport) ([]MACAddressChange, error) {
+	for _, use := range report.Uses {
+		g.markUsed(use.MACAddress)
+	}
+
+	var stale []MACAddressUse
+	stale = append(stale, report.Invalid...)
+
+	duplicates := make([]string, 0, len(report.Duplicates))
+	for mac := range report.Duplicates {
+		duplicates = append(duplicates, mac)
+	}
+	sort.Strings(duplicates)
+
+	for _, mac := range duplicates {
+		stale = append(stale, report.Duplicates[mac][1:]...)
+	}
+
+	var changes []MACAddressChange
+	for _, use := range stale {
+		mac, err := g.Regenerate(use)
+		if err != nil {
+			return changes, fmt.Errorf("unable to regenerate MAC address of %s slot %d: %v", use.MachineName, use.Slot, err)
+		}
+
+		changes = append(changes, MACAddressChange{use, mac})
+	}
+
+	return changes, nil
+}
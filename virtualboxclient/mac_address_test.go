@@ -0,0 +1,51 @@
+package virtualboxclient
+
+import "testing"
+
+func TestNormalizeMACAddress(t *testing.T) {
+	tests := []struct {
+		mac  string
+		want string
+	}{
+		{"080027ABCDEF", "080027ABCDEF"},
+		{"08:00:27:ab:cd:ef", "080027ABCDEF"},
+		{"08-00-27-AB-CD-EF", "080027ABCDEF"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeMACAddress(tt.mac); got != tt.want {
+			t.Errorf("NormalizeMACAddress(%q) = %q, want %q", tt.mac, got, tt.want)
+		}
+	}
+}
+
+func TestValidateMACAddress(t *testing.T) {
+	tests := []struct {
+		mac     string
+		wantErr bool
+	}{
+		{mac: "080027ABCDEF"},
+		{mac: "08:00:27:ab:cd:ef"},
+		{mac: "08-00-27-AB-CD-EF"},
+		{mac: "020000000001"},
+		{mac: "", wantErr: true},
+		{mac: "080027ABCDE", wantErr: true},
+		{mac: "080027ABCDEF00", wantErr: true},
+		{mac: "080027ABCDEG", wantErr: true},
+		{mac: "08.00.27.AB.CD.EF", wantErr: true},
+		{mac: "000000000000", wantErr: true},
+		{mac: "010000000000", wantErr: true},
+		{mac: "FFFFFFFFFFFF", wantErr: true},
+		{mac: "33:33:00:00:00:01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateMACAddress(tt.mac)
+		if tt.wantErr && err == nil {
+			t.Errorf("ValidateMACAddress(%q) = nil, want error", tt.mac)
+		} else if !tt.wantErr && err != nil {
+			t.Errorf("ValidateMACAddress(%q) returned error: %v", tt.mac, err)
+		}
+	}
+}
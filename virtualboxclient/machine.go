@@ -1,6 +1,8 @@
 package virtualboxclient
 
 import (
+	"fmt"
+
 	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
 )
 
@@ -9,6 +11,52 @@ type Machine struct {
 	managedObjectId string
 }
 
+func (m *Machine) FindSnapshot(nameOrId string) (*Snapshot, error) {
+	request := vboxwebsrv.IMachinefindSnapshot{This: m.managedObjectId, NameOrId: nameOrId}
+
+	response, err := m.virtualbox.IMachinefindSnapshot(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Snapshot{m.virtualbox, response.Returnval}, nil
+}
+
+// GetAccessible reports whether the machine's settings could be loaded. Most
+// other getters fail for inaccessible machines.
+func (m *Machine) GetAccessible() (bool, error) {
+	request := vboxwebsrv.IMachinegetAccessible{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMachinegetAccessible(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Machine) GetBandwidthControl() (*BandwidthControl, error) {
+	request := vboxwebsrv.IMachinegetBandwidthControl{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMachinegetBandwidthControl(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &BandwidthControl{m.virtualbox, response.Returnval}, nil
+}
+
+func (m *Machine) GetCPUCount() (uint32, error) {
+	request := vboxwebsrv.IMachinegetCPUCount{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMachinegetCPUCount(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
 func (m *Machine) GetChipsetType() (*vboxwebsrv.ChipsetType, error) {
 	request := vboxwebsrv.IMachinegetChipsetType{This: m.managedObjectId}
 
@@ -20,6 +68,83 @@ func (m *Machine) GetChipsetType() (*vboxwebsrv.ChipsetType, error) {
 	return response.Returnval, nil
 }
 
+// GetGuest returns the guest of a running machine. The machine is locked
+// through a separate web session, logged on with the client's credentials,
+// until Guest.Release is called, so the client can still lock machines in the
+// meantime.
+func (m *Machine) GetGuest() (guest *Guest, err error) {
+	id, err := m.GetId()
+	if err != nil {
+		return nil, err
+	}
+
+	vb := m.virtualbox.newWebSession()
+	if err := vb.Logon(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			vb.Logoff()
+		}
+	}()
+
+	machine, err := vb.FindMachine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := vb.GetSessionObject()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := machine.LockMachine(session, vboxwebsrv.LockTypeShared); err != nil {
+		return nil, err
+	}
+
+	console, err := session.GetConsole()
+	if err == nil && console == nil {
+		err = fmt.Errorf("machine is not running")
+	}
+	if err != nil {
+		session.UnlockMachine()
+		return nil, err
+	}
+
+	guest, err = console.GetGuest()
+	if err != nil {
+		session.UnlockMachine()
+		return nil, err
+	}
+	guest.session = session
+
+	return guest, nil
+}
+
+// GetGuestPropertyValue returns the value of a guest property, or "" if it is
+// not set.
+func (m *Machine) GetGuestPropertyValue(property string) (string, error) {
+	request := vboxwebsrv.IMachinegetGuestPropertyValue{This: m.managedObjectId, Property: property}
+
+	response, err := m.virtualbox.IMachinegetGuestPropertyValue(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Machine) GetId() (string, error) {
+	request := vboxwebsrv.IMachinegetId{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMachinegetId(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
 func (m *Machine) GetMediumAttachments() ([]*vboxwebsrv.IMediumAttachment, error) {
 	request := vboxwebsrv.IMachinegetMediumAttachments{This: m.managedObjectId}
 
@@ -31,6 +156,29 @@ func (m *Machine) GetMediumAttachments() ([]*vboxwebsrv.IMediumAttachment, error
 	return response.Returnval, nil
 }
 
+// GetMemorySize returns the machine's RAM in megabytes.
+func (m *Machine) GetMemorySize() (uint32, error) {
+	request := vboxwebsrv.IMachinegetMemorySize{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMachinegetMemorySize(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Machine) GetName() (string, error) {
+	request := vboxwebsrv.IMachinegetName{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMachinegetName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
 func (m *Machine) GetNetworkAdapter(slot uint32) (*NetworkAdapter, error) {
 	request := vboxwebsrv.IMachinegetNetworkAdapter{This: m.managedObjectId, Slot: slot}
 
@@ -42,6 +190,34 @@ func (m *Machine) GetNetworkAdapter(slot uint32) (*NetworkAdapter, error) {
 	return &NetworkAdapter{m.virtualbox, response.Returnval}, nil
 }
 
+// GetNetworkAdapters returns every network adapter slot the machine's chipset
+// supports, whether the adapter is enabled or not.
+func (m *Machine) GetNetworkAdapters() ([]*NetworkAdapter, error) {
+	chipset, err := m.GetChipsetType()
+	if err != nil {
+		return nil, err
+	}
+
+	systemProperties, err := m.virtualbox.GetSystemProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := systemProperties.GetMaxNetworkAdapters(chipset)
+	if err != nil {
+		return nil, err
+	}
+
+	adapters := make([]*NetworkAdapter, count)
+	for slot := range adapters {
+		if adapters[slot], err = m.GetNetworkAdapter(uint32(slot)); err != nil {
+			return nil, err
+		}
+	}
+
+	return adapters, nil
+}
+
 func (m *Machine) GetSettingsFilePath() (string, error) {
 	request := vboxwebsrv.IMachinegetSettingsFilePath{This: m.managedObjectId}
 
@@ -53,6 +229,17 @@ func (m *Machine) GetSettingsFilePath() (string, error) {
 	return response.Returnval, nil
 }
 
+func (m *Machine) GetState() (*vboxwebsrv.MachineState, error) {
+	request := vboxwebsrv.IMachinegetState{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMachinegetState(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
 func (m *Machine) GetStorageControllers() ([]*StorageController, error) {
 	request := vboxwebsrv.IMachinegetStorageControllers{This: m.managedObjectId}
 
@@ -68,3 +255,80 @@ func (m *Machine) GetStorageControllers() ([]*StorageController, error) {
 
 	return storageControllers, nil
 }
+
+func (m *Machine) LockMachine(session *Session, lockType vboxwebsrv.LockType) error {
+	request := vboxwebsrv.IMachinelockMachine{This: m.managedObjectId, Session: session.managedObjectId, LockType: &lockType}
+
+	_, err := m.virtualbox.IMachinelockMachine(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (m *Machine) SaveSettings() error {
+	request := vboxwebsrv.IMachinesaveSettings{This: m.managedObjectId}
+
+	_, err := m.virtualbox.IMachinesaveSettings(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// SetBandwidthGroupForDevice moves the medium attached to the given controller
+// port and device into group, or out of any group if group is nil. The machine
+// must be locked for writing.
+func (m *Machine) SetBandwidthGroupForDevice(controllerName string, controllerPort int32, device int32, group *BandwidthGroup) error {
+	if group == nil {
+		request := vboxwebsrv.IMachinesetNoBandwidthGroupForDevice{This: m.managedObjectId, Name: controllerName, ControllerPort: controllerPort, Device: device}
+
+		_, err := m.virtualbox.IMachinesetNoBandwidthGroupForDevice(&request)
+		if err != nil {
+			return err // TODO: Wrap the error
+		}
+
+		return nil
+	}
+
+	request := vboxwebsrv.IMachinesetBandwidthGroupForDevice{This: m.managedObjectId, Name: controllerName, ControllerPort: controllerPort, Device: device, BandwidthGroup: group.managedObjectId}
+
+	_, err := m.virtualbox.IMachinesetBandwidthGroupForDevice(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// WithLock locks the machine, calls fn with the mutable machine and saves the
+// settings if fn succeeds. The machine is unlocked again before returning.
+// Use LockTypeShared to change the settings of a running machine.
+func (m *Machine) WithLock(lockType vboxwebsrv.LockType, fn func(mutable *Machine) error) (err error) {
+	session, err := m.virtualbox.GetSessionObject()
+	if err != nil {
+		return err
+	}
+
+	if err := m.LockMachine(session, lockType); err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := session.UnlockMachine(); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	mutable, err := session.GetMachine()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(mutable); err != nil {
+		return err
+	}
+
+	return mutable.SaveSettings()
+}
@@ -1,6 +1,9 @@
 package virtualboxclient
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
 )
 
@@ -9,7 +12,93 @@ type Medium struct {
 	managedObjectId string
 }
 
+// CloneTo copies the contents of m to target, which must not have storage yet.
+// The format of the copy is the one target was created with. If parent is not
+// nil, target becomes a differencing image of it. The variant is checked
+// against the capabilities of target's format first.
+func (m *Medium) CloneTo(target *Medium, variant []*vboxwebsrv.MediumVariant, parent *Medium) (*Progress, error) {
+	if err := target.validateVariant(variant); err != nil {
+		return nil, err
+	}
+
+	request := vboxwebsrv.IMediumcloneTo{This: m.managedObjectId, Target: target.managedObjectId, Variant: variant}
+	if parent != nil {
+		request.Parent = parent.managedObjectId
+	}
+
+	response, err := m.virtualbox.IMediumcloneTo(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+// CloneToBase copies m to target, flattening any differencing chain into a
+// single base image. The variant is checked against the capabilities of
+// target's format first.
+func (m *Medium) CloneToBase(target *Medium, variant []*vboxwebsrv.MediumVariant) (*Progress, error) {
+	if err := target.validateVariant(variant); err != nil {
+		return nil, err
+	}
+
+	request := vboxwebsrv.IMediumcloneToBase{This: m.managedObjectId, Target: target.managedObjectId, Variant: variant}
+
+	response, err := m.virtualbox.IMediumcloneToBase(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+// CloneToFormat creates a new hard disk with the given format and location
+// and clones m into it as a base image. The new hard disk is closed again if
+// the clone cannot be started.
+func (m *Medium) CloneToFormat(format, location string, variant []*vboxwebsrv.MediumVariant) (*Medium, *Progress, error) {
+	target, err := m.virtualbox.CreateHardDisk(format, location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	progress, err := m.CloneToBase(target, variant)
+	if err != nil {
+		target.Close()
+		return nil, nil, err
+	}
+
+	return target, progress, nil
+}
+
+func (m *Medium) Close() error {
+	request := vboxwebsrv.IMediumclose{This: m.managedObjectId}
+
+	_, err := m.virtualbox.IMediumclose(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (m *Medium) Compact() (*Progress, error) {
+	request := vboxwebsrv.IMediumcompact{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumcompact(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+// CreateBaseStorage creates the storage for a new base image. The variant is
+// checked against the capabilities of the medium's format first.
 func (m *Medium) CreateBaseStorage(logicalSize int64, variant []*vboxwebsrv.MediumVariant) (*Progress, error) {
+	if err := m.validateVariant(variant); err != nil {
+		return nil, err
+	}
+
 	request := vboxwebsrv.IMediumcreateBaseStorage{This: m.managedObjectId, LogicalSize: logicalSize, Variant: variant}
 
 	response, err := m.virtualbox.IMediumcreateBaseStorage(&request)
@@ -17,8 +106,24 @@ func (m *Medium) CreateBaseStorage(logicalSize int64, variant []*vboxwebsrv.Medi
 		return nil, err // TODO: Wrap the error
 	}
 
-	// TODO: See if we need to do anything with the response
-	return &Progress{managedObjectId: response.Returnval}, nil
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+// CreateDiffStorage creates target as a differencing image on top of m. The
+// variant is checked against the capabilities of target's format first.
+func (m *Medium) CreateDiffStorage(target *Medium, variant []*vboxwebsrv.MediumVariant) (*Progress, error) {
+	if err := target.validateVariant(variant); err != nil {
+		return nil, err
+	}
+
+	request := vboxwebsrv.IMediumcreateDiffStorage{This: m.managedObjectId, Target: target.managedObjectId, Variant: variant}
+
+	response, err := m.virtualbox.IMediumcreateDiffStorage(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{m.virtualbox, response.Returnval}, nil
 }
 
 func (m *Medium) DeleteStorage() (*Progress, error) {
@@ -29,6 +134,273 @@ func (m *Medium) DeleteStorage() (*Progress, error) {
 		return nil, err // TODO: Wrap the error
 	}
 
-	// TODO: See if we need to do anything with the response
-	return &Progress{managedObjectId: response.Returnval}, nil
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+func (m *Medium) GetBase() (*Medium, error) {
+	request := vboxwebsrv.IMediumgetBase{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetBase(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Medium{m.virtualbox, response.Returnval}, nil
+}
+
+func (m *Medium) GetChildren() ([]*Medium, error) {
+	request := vboxwebsrv.IMediumgetChildren{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetChildren(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	children := make([]*Medium, len(response.Returnval))
+	for i, oid := range response.Returnval {
+		children[i] = &Medium{m.virtualbox, oid}
+	}
+
+	return children, nil
+}
+
+func (m *Medium) GetDeviceType() (*vboxwebsrv.DeviceType, error) {
+	request := vboxwebsrv.IMediumgetDeviceType{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetDeviceType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Medium) GetId() (string, error) {
+	request := vboxwebsrv.IMediumgetId{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetId(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Medium) GetLocation() (string, error) {
+	request := vboxwebsrv.IMediumgetLocation{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetLocation(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Medium) GetMachineIds() ([]string, error) {
+	request := vboxwebsrv.IMediumgetMachineIds{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetMachineIds(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Medium) GetMediumFormat() (*MediumFormat, error) {
+	request := vboxwebsrv.IMediumgetMediumFormat{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetMediumFormat(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &MediumFormat{m.virtualbox, response.Returnval}, nil
+}
+
+func (m *Medium) GetName() (string, error) {
+	request := vboxwebsrv.IMediumgetName{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetParent returns nil if the medium is not a differencing image.
+func (m *Medium) GetParent() (*Medium, error) {
+	request := vboxwebsrv.IMediumgetParent{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetParent(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	if response.Returnval == "" {
+		return nil, nil
+	}
+
+	return &Medium{m.virtualbox, response.Returnval}, nil
+}
+
+// GetProperties returns the named properties, or all properties if no names are
+// given.
+func (m *Medium) GetProperties(names ...string) (map[string]string, error) {
+	request := vboxwebsrv.IMediumgetProperties{This: m.managedObjectId, Names: strings.Join(names, ",")}
+
+	response, err := m.virtualbox.IMediumgetProperties(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	properties := make(map[string]string, len(response.ReturnNames))
+	for i, name := range response.ReturnNames {
+		if i < len(response.Returnval) {
+			properties[name] = response.Returnval[i]
+		}
+	}
+
+	return properties, nil
+}
+
+func (m *Medium) GetProperty(name string) (string, error) {
+	request := vboxwebsrv.IMediumgetProperty{This: m.managedObjectId, Name: name}
+
+	response, err := m.virtualbox.IMediumgetProperty(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Medium) GetSnapshotIds(machineId string) ([]string, error) {
+	request := vboxwebsrv.IMediumgetSnapshotIds{This: m.managedObjectId, MachineId: machineId}
+
+	response, err := m.virtualbox.IMediumgetSnapshotIds(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (m *Medium) GetState() (*vboxwebsrv.MediumState, error) {
+	request := vboxwebsrv.IMediumgetState{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumgetState(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// MergeTo merges the contents of m into target, which must be an ancestor or
+// descendant of m in the same differencing chain.
+func (m *Medium) MergeTo(target *Medium) (*Progress, error) {
+	request := vboxwebsrv.IMediummergeTo{This: m.managedObjectId, Target: target.managedObjectId}
+
+	response, err := m.virtualbox.IMediummergeTo(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+// RefreshState rechecks whether the medium's storage is accessible and returns
+// the updated state.
+func (m *Medium) RefreshState() (*vboxwebsrv.MediumState, error) {
+	request := vboxwebsrv.IMediumrefreshState{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumrefreshState(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// Reset discards the contents of a differencing image.
+func (m *Medium) Reset() (*Progress, error) {
+	request := vboxwebsrv.IMediumreset{This: m.managedObjectId}
+
+	response, err := m.virtualbox.IMediumreset(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+func (m *Medium) Resize(logicalSize int64) (*Progress, error) {
+	request := vboxwebsrv.IMediumresize{This: m.managedObjectId, LogicalSize: logicalSize}
+
+	response, err := m.virtualbox.IMediumresize(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+// SetLocation moves the medium's storage to location.
+func (m *Medium) SetLocation(location string) (*Progress, error) {
+	request := vboxwebsrv.IMediumsetLocation{This: m.managedObjectId, Location: location}
+
+	response, err := m.virtualbox.IMediumsetLocation(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Progress{m.virtualbox, response.Returnval}, nil
+}
+
+// SetProperties sets several properties at once. An empty value resets the
+// property to its default.
+func (m *Medium) SetProperties(properties map[string]string) error {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = properties[name]
+	}
+
+	request := vboxwebsrv.IMediumsetProperties{This: m.managedObjectId, Names: names, Values: values}
+
+	_, err := m.virtualbox.IMediumsetProperties(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (m *Medium) SetProperty(name, value string) error {
+	request := vboxwebsrv.IMediumsetProperty{This: m.managedObjectId, Name: name, Value: value}
+
+	_, err := m.virtualbox.IMediumsetProperty(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// validateVariant checks variant against the capabilities of m's format.
+func (m *Medium) validateVariant(variant []*vboxwebsrv.MediumVariant) error {
+	format, err := m.GetMediumFormat()
+	if err != nil {
+		return err
+	}
+
+	return format.ValidateVariant(variant)
 }
@@ -0,0 +1,200 @@
+package virtualboxclient
+
+import (
+	"fmt"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// MediumFormatCapabilities is the bitmask form of the capability list reported
+// by IMediumFormat, using the values from the VirtualBox API.
+type MediumFormatCapabilities uint32
+
+const (
+	MediumFormatCapabilityUuid          MediumFormatCapabilities = 0x01
+	MediumFormatCapabilityCreateFixed   MediumFormatCapabilities = 0x02
+	MediumFormatCapabilityCreateDynamic MediumFormatCapabilities = 0x04
+	MediumFormatCapabilityCreateSplit2G MediumFormatCapabilities = 0x08
+	MediumFormatCapabilityDifferencing  MediumFormatCapabilities = 0x10
+	MediumFormatCapabilityAsynchronous  MediumFormatCapabilities = 0x20
+	MediumFormatCapabilityFile          MediumFormatCapabilities = 0x40
+	MediumFormatCapabilityProperties    MediumFormatCapabilities = 0x80
+	MediumFormatCapabilityTcpNetworking MediumFormatCapabilities = 0x100
+	MediumFormatCapabilityVFS           MediumFormatCapabilities = 0x200
+)
+
+var mediumFormatCapabilities = map[vboxwebsrv.MediumFormatCapabilities]MediumFormatCapabilities{
+	vboxwebsrv.MediumFormatCapabilitiesUuid:          MediumFormatCapabilityUuid,
+	vboxwebsrv.MediumFormatCapabilitiesCreateFixed:   MediumFormatCapabilityCreateFixed,
+	vboxwebsrv.MediumFormatCapabilitiesCreateDynamic: MediumFormatCapabilityCreateDynamic,
+	vboxwebsrv.MediumFormatCapabilitiesCreateSplit2G: MediumFormatCapabilityCreateSplit2G,
+	vboxwebsrv.MediumFormatCapabilitiesDifferencing:  MediumFormatCapabilityDifferencing,
+	vboxwebsrv.MediumFormatCapabilitiesAsynchronous:  MediumFormatCapabilityAsynchronous,
+	vboxwebsrv.MediumFormatCapabilitiesFile:          MediumFormatCapabilityFile,
+	vboxwebsrv.MediumFormatCapabilitiesProperties:    MediumFormatCapabilityProperties,
+	vboxwebsrv.MediumFormatCapabilitiesTcpNetworking: MediumFormatCapabilityTcpNetworking,
+	vboxwebsrv.MediumFormatCapabilitiesVFS:           MediumFormatCapabilityVFS,
+}
+
+func (c MediumFormatCapabilities) Has(capability MediumFormatCapabilities) bool {
+	return c&capability == capability
+}
+
+type MediumFormatFileExtension struct {
+	Extension  string
+	DeviceType vboxwebsrv.DeviceType
+}
+
+type MediumFormatProperty struct {
+	Name        string
+	Description string
+	Type        vboxwebsrv.DataType
+	Default     string
+
+	Mandatory bool
+	Expert    bool
+	Array     bool
+}
+
+// Values of the flags returned by IMediumFormat::describeProperties
+const (
+	dataFlagsMandatory = 0x01
+	dataFlagsExpert    = 0x02
+	dataFlagsArray     = 0x04
+)
+
+type MediumFormat struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (mf *MediumFormat) DescribeFileExtensions() ([]MediumFormatFileExtension, error) {
+	request := vboxwebsrv.IMediumFormatdescribeFileExtensions{This: mf.managedObjectId}
+
+	response, err := mf.virtualbox.IMediumFormatdescribeFileExtensions(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	extensions := make([]MediumFormatFileExtension, len(response.Extensions))
+	for i, extension := range response.Extensions {
+		extensions[i].Extension = extension
+		if i < len(response.Types) && response.Types[i] != nil {
+			extensions[i].DeviceType = *response.Types[i]
+		}
+	}
+
+	return extensions, nil
+}
+
+func (mf *MediumFormat) DescribeProperties() ([]MediumFormatProperty, error) {
+	request := vboxwebsrv.IMediumFormatdescribeProperties{This: mf.managedObjectId}
+
+	response, err := mf.virtualbox.IMediumFormatdescribeProperties(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	properties := make([]MediumFormatProperty, len(response.Names))
+	for i, name := range response.Names {
+		properties[i].Name = name
+		if i < len(response.Descriptions) {
+			properties[i].Description = response.Descriptions[i]
+		}
+		if i < len(response.Types) && response.Types[i] != nil {
+			properties[i].Type = *response.Types[i]
+		}
+		if i < len(response.Defaults) {
+			properties[i].Default = response.Defaults[i]
+		}
+		if i < len(response.Flags) {
+			properties[i].Mandatory = response.Flags[i]&dataFlagsMandatory != 0
+			properties[i].Expert = response.Flags[i]&dataFlagsExpert != 0
+			properties[i].Array = response.Flags[i]&dataFlagsArray != 0
+		}
+	}
+
+	return properties, nil
+}
+
+func (mf *MediumFormat) GetCapabilities() (MediumFormatCapabilities, error) {
+	request := vboxwebsrv.IMediumFormatgetCapabilities{This: mf.managedObjectId}
+
+	response, err := mf.virtualbox.IMediumFormatgetCapabilities(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	var capabilities MediumFormatCapabilities
+	for _, capability := range response.Returnval {
+		if capability != nil {
+			capabilities |= mediumFormatCapabilities[*capability]
+		}
+	}
+
+	return capabilities, nil
+}
+
+func (mf *MediumFormat) GetId() (string, error) {
+	request := vboxwebsrv.IMediumFormatgetId{This: mf.managedObjectId}
+
+	response, err := mf.virtualbox.IMediumFormatgetId(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (mf *MediumFormat) GetName() (string, error) {
+	request := vboxwebsrv.IMediumFormatgetName{This: mf.managedObjectId}
+
+	response, err := mf.virtualbox.IMediumFormatgetName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// ValidateVariant checks that the format can create storage with the given
+// variant.
+func (mf *MediumFormat) ValidateVariant(variant []*vboxwebsrv.MediumVariant) error {
+	capabilities, err := mf.GetCapabilities()
+	if err != nil {
+		return err
+	}
+
+	id, err := mf.GetId()
+	if err != nil {
+		return err
+	}
+
+	fixed := false
+	for _, v := range variant {
+		if v == nil {
+			continue
+		}
+
+		var required MediumFormatCapabilities
+		switch *v {
+		case vboxwebsrv.MediumVariantFixed:
+			fixed = true
+			required = MediumFormatCapabilityCreateFixed
+		case vboxwebsrv.MediumVariantVmdkSplit2G:
+			required = MediumFormatCapabilityCreateSplit2G
+		case vboxwebsrv.MediumVariantDiff:
+			required = MediumFormatCapabilityDifferencing
+		}
+
+		if !capabilities.Has(required) {
+			return fmt.Errorf("medium format %s does not support variant %s", id, *v)
+		}
+	}
+
+	if !fixed && !capabilities.Has(MediumFormatCapabilityCreateDynamic) {
+		return fmt.Errorf("medium format %s does not support dynamically allocated storage", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MediumTree is a medium together with the differencing images derived from it.
+type MediumTree struct {
+	Medium   *Medium
+	Children []*MediumTree
+}
+
+// GetTree returns the tree of differencing images rooted at m. Call GetBase
+// first to get the whole chain a differencing image belongs to.
+func (m *Medium) GetTree() (*MediumTree, error) {
+	children, err := m.GetChildren()
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &MediumTree{Medium: m, Children: make([]*MediumTree, len(children))}
+	for i, child := range children {
+		if tree.Children[i], err = child.GetTree(); err != nil {
+			return nil, err
+		}
+	}
+
+	return tree, nil
+}
+
+// Walk calls fn for every medium in the tree, parents before children. The
+// root has depth 0. Walk stops at the first error returned by fn.
+func (t *MediumTree) Walk(fn func(m *Medium, depth int) error) error {
+	return t.walk(fn, 0)
+}
+
+func (t *MediumTree) walk(fn func(m *Medium, depth int) error, depth int) error {
+	if err := fn(t.Medium, depth); err != nil {
+		return err
+	}
+
+	for _, child := range t.Children {
+		if err := child.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Render writes the tree to w, one medium per line, indented by depth and
+// followed by the machines (and their snapshots) the medium is attached to.
+func (t *MediumTree) Render(w io.Writer) error {
+	return t.Walk(func(m *Medium, depth int) error {
+		name, err := m.GetName()
+		if err != nil {
+			return err
+		}
+
+		id, err := m.GetId()
+		if err != nil {
+			return err
+		}
+
+		owners, err := m.describeOwners()
+		if err != nil {
+			return err
+		}
+
+		line := fmt.Sprintf("%s%s {%s}", strings.Repeat("  ", depth), name, id)
+		if len(owners) > 0 {
+			line += " [" + strings.Join(owners, "; ") + "]"
+		}
+
+		_, err = fmt.Fprintln(w, line)
+		return err
+	})
+}
+
+// describeOwners returns one entry per machine the medium is attached to, in
+// the form "machine (snapshot, ...)".
+func (m *Medium) describeOwners() ([]string, error) {
+	machineIds, err := m.GetMachineIds()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make([]string, len(machineIds))
+	for i, machineId := range machineIds {
+		machine, err := m.virtualbox.FindMachine(machineId)
+		if err != nil {
+			return nil, err
+		}
+
+		machineName, err := machine.GetName()
+		if err != nil {
+			return nil, err
+		}
+
+		snapshotIds, err := m.GetSnapshotIds(machineId)
+		if err != nil {
+			return nil, err
+		}
+
+		var snapshots []string
+		for _, snapshotId := range snapshotIds {
+			// The machine's own ID stands for its current state
+			if snapshotId == machineId {
+				snapshots = append(snapshots, "current state")
+				continue
+			}
+
+			snapshot, err := machine.FindSnapshot(snapshotId)
+			if err != nil {
+				return nil, err
+			}
+
+			snapshotName, err := snapshot.GetName()
+			if err != nil {
+				return nil, err
+			}
+
+			snapshots = append(snapshots, snapshotName)
+		}
+
+		owners[i] = machineName
+		if len(snapshots) > 0 {
+			owners[i] += " (" + strings.Join(snapshots, ", ") + ")"
+		}
+	}
+
+	return owners, nil
+}
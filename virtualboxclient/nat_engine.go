@@ -0,0 +1,369 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// NATAliasMode is the bitmask form of the NAT engine's alias mode, using the
+// values from the VirtualBox API.
+type NATAliasMode uint32
+
+const (
+	NATAliasModeAliasLog          NATAliasMode = 0x01
+	NATAliasModeAliasProxyOnly    NATAliasMode = 0x02
+	NATAliasModeAliasUseSamePorts NATAliasMode = 0x04
+)
+
+// NATRedirect is a port-forwarding rule of a NAT engine.
+type NATRedirect struct {
+	Name      string
+	Protocol  vboxwebsrv.NATProtocol
+	HostIP    string
+	HostPort  uint16
+	GuestIP   string
+	GuestPort uint16
+}
+
+// NATNetworkSettings holds the MTU and buffer sizes of a NAT engine. Zero
+// means the built-in default.
+type NATNetworkSettings struct {
+	MTU       uint32
+	SockSnd   uint32
+	SockRcv   uint32
+	TCPWndSnd uint32
+	TCPWndRcv uint32
+}
+
+// Protocol numbers used in the string form of redirects
+var natProtocols = map[string]vboxwebsrv.NATProtocol{
+	"0": vboxwebsrv.NATProtocolUDP,
+	"1": vboxwebsrv.NATProtocolTCP,
+}
+
+// parseNATRedirect parses a rule as returned by INATEngine::getRedirects, in
+// the form "name,protocol,hostIP,hostPort,guestIP,guestPort".
+func parseNATRedirect(rule string) (NATRedirect, error) {
+	fields := strings.Split(rule, ",")
+	if len(fields) != 6 {
+		return NATRedirect{}, fmt.Errorf("malformed NAT redirect %q", rule)
+	}
+
+	protocol, ok := natProtocols[fields[1]]
+	if !ok {
+		return NATRedirect{}, fmt.Errorf("unknown protocol in NAT redirect %q", rule)
+	}
+
+	hostPort, err := strconv.ParseUint(fields[3], 10, 16)
+	if err != nil {
+		return NATRedirect{}, fmt.Errorf("malformed host port in NAT redirect %q", rule)
+	}
+
+	guestPort, err := strconv.ParseUint(fields[5], 10, 16)
+	if err != nil {
+		return NATRedirect{}, fmt.Errorf("malformed guest port in NAT redirect %q", rule)
+	}
+
+	return NATRedirect{
+		Name:      fields[0],
+		Protocol:  protocol,
+		HostIP:    fields[2],
+		HostPort:  uint16(hostPort),
+		GuestIP:   fields[4],
+		GuestPort: uint16(guestPort),
+	}, nil
+}
+
+type NATEngine struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+// AddRedirect adds a port-forwarding rule. Empty IP addresses mean any host
+// address and the guest's DHCP address respectively.
+func (ne *NATEngine) AddRedirect(redirect NATRedirect) error {
+	request := vboxwebsrv.INATEngineaddRedirect{
+		This:      ne.managedObjectId,
+		Name:      redirect.Name,
+		Proto:     &redirect.Protocol,
+		HostIP:    redirect.HostIP,
+		HostPort:  redirect.HostPort,
+		GuestIP:   redirect.GuestIP,
+		GuestPort: redirect.GuestPort,
+	}
+
+	_, err := ne.virtualbox.INATEngineaddRedirect(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) GetAliasMode() (NATAliasMode, error) {
+	request := vboxwebsrv.INATEnginegetAliasMode{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetAliasMode(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return NATAliasMode(response.Returnval), nil
+}
+
+func (ne *NATEngine) GetDNSPassDomain() (bool, error) {
+	request := vboxwebsrv.INATEnginegetDNSPassDomain{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetDNSPassDomain(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ne *NATEngine) GetDNSProxy() (bool, error) {
+	request := vboxwebsrv.INATEnginegetDNSProxy{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetDNSProxy(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ne *NATEngine) GetDNSUseHostResolver() (bool, error) {
+	request := vboxwebsrv.INATEnginegetDNSUseHostResolver{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetDNSUseHostResolver(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetHostIP returns the host address that forwarded ports bind to by default.
+func (ne *NATEngine) GetHostIP() (string, error) {
+	request := vboxwebsrv.INATEnginegetHostIP{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetHostIP(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetNetwork returns the guest network in CIDR notation, or an empty string
+// for the default 10.0.x.0/24.
+func (ne *NATEngine) GetNetwork() (string, error) {
+	request := vboxwebsrv.INATEnginegetNetwork{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetNetwork(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ne *NATEngine) GetNetworkSettings() (*NATNetworkSettings, error) {
+	request := vboxwebsrv.INATEnginegetNetworkSettings{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetNetworkSettings(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &NATNetworkSettings{
+		MTU:       response.Mtu,
+		SockSnd:   response.SockSnd,
+		SockRcv:   response.SockRcv,
+		TCPWndSnd: response.TcpWndSnd,
+		TCPWndRcv: response.TcpWndRcv,
+	}, nil
+}
+
+func (ne *NATEngine) GetRedirects() ([]NATRedirect, error) {
+	request := vboxwebsrv.INATEnginegetRedirects{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetRedirects(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	redirects := make([]NATRedirect, len(response.Returnval))
+	for i, rule := range response.Returnval {
+		if redirects[i], err = parseNATRedirect(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	return redirects, nil
+}
+
+func (ne *NATEngine) GetTFTPBootFile() (string, error) {
+	request := vboxwebsrv.INATEnginegetTFTPBootFile{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetTFTPBootFile(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ne *NATEngine) GetTFTPNextServer() (string, error) {
+	request := vboxwebsrv.INATEnginegetTFTPNextServer{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetTFTPNextServer(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ne *NATEngine) GetTFTPPrefix() (string, error) {
+	request := vboxwebsrv.INATEnginegetTFTPPrefix{This: ne.managedObjectId}
+
+	response, err := ne.virtualbox.INATEnginegetTFTPPrefix(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ne *NATEngine) RemoveRedirect(name string) error {
+	request := vboxwebsrv.INATEngineremoveRedirect{This: ne.managedObjectId, Name: name}
+
+	_, err := ne.virtualbox.INATEngineremoveRedirect(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetAliasMode(aliasMode NATAliasMode) error {
+	request := vboxwebsrv.INATEnginesetAliasMode{This: ne.managedObjectId, AliasMode: uint32(aliasMode)}
+
+	_, err := ne.virtualbox.INATEnginesetAliasMode(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetDNSPassDomain(dnsPassDomain bool) error {
+	request := vboxwebsrv.INATEnginesetDNSPassDomain{This: ne.managedObjectId, DNSPassDomain: dnsPassDomain}
+
+	_, err := ne.virtualbox.INATEnginesetDNSPassDomain(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetDNSProxy(dnsProxy bool) error {
+	request := vboxwebsrv.INATEnginesetDNSProxy{This: ne.managedObjectId, DNSProxy: dnsProxy}
+
+	_, err := ne.virtualbox.INATEnginesetDNSProxy(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetDNSUseHostResolver(dnsUseHostResolver bool) error {
+	request := vboxwebsrv.INATEnginesetDNSUseHostResolver{This: ne.managedObjectId, DNSUseHostResolver: dnsUseHostResolver}
+
+	_, err := ne.virtualbox.INATEnginesetDNSUseHostResolver(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetHostIP(hostIP string) error {
+	request := vboxwebsrv.INATEnginesetHostIP{This: ne.managedObjectId, HostIP: hostIP}
+
+	_, err := ne.virtualbox.INATEnginesetHostIP(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetNetwork(network string) error {
+	request := vboxwebsrv.INATEnginesetNetwork{This: ne.managedObjectId, Network: network}
+
+	_, err := ne.virtualbox.INATEnginesetNetwork(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetNetworkSettings(settings *NATNetworkSettings) error {
+	request := vboxwebsrv.INATEnginesetNetworkSettings{
+		This:      ne.managedObjectId,
+		Mtu:       settings.MTU,
+		SockSnd:   settings.SockSnd,
+		SockRcv:   settings.SockRcv,
+		TcpWndSnd: settings.TCPWndSnd,
+		TcpWndRcv: settings.TCPWndRcv,
+	}
+
+	_, err := ne.virtualbox.INATEnginesetNetworkSettings(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetTFTPBootFile(tftpBootFile string) error {
+	request := vboxwebsrv.INATEnginesetTFTPBootFile{This: ne.managedObjectId, TFTPBootFile: tftpBootFile}
+
+	_, err := ne.virtualbox.INATEnginesetTFTPBootFile(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetTFTPNextServer(tftpNextServer string) error {
+	request := vboxwebsrv.INATEnginesetTFTPNextServer{This: ne.managedObjectId, TFTPNextServer: tftpNextServer}
+
+	_, err := ne.virtualbox.INATEnginesetTFTPNextServer(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (ne *NATEngine) SetTFTPPrefix(tftpPrefix string) error {
+	request := vboxwebsrv.INATEnginesetTFTPPrefix{This: ne.managedObjectId, TFTPPrefix: tftpPrefix}
+
+	_, err := ne.virtualbox.INATEnginesetTFTPPrefix(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
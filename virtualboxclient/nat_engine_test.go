@@ -0,0 +1,56 @@
+package virtualboxclient
+
+import (
+	"testing"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+func TestParseNATRedirect(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    NATRedirect
+		wantErr bool
+	}{
+		{
+			rule: "ssh,1,,2222,,22",
+			want: NATRedirect{Name: "ssh", Protocol: vboxwebsrv.NATProtocolTCP, HostPort: 2222, GuestPort: 22},
+		},
+		{
+			rule: "dns,0,127.0.0.1,5353,10.0.2.15,53",
+			want: NATRedirect{Name: "dns", Protocol: vboxwebsrv.NATProtocolUDP, HostIP: "127.0.0.1", HostPort: 5353, GuestIP: "10.0.2.15", GuestPort: 53},
+		},
+		{
+			rule: "web6,1,::1,8080,,80",
+			want: NATRedirect{Name: "web6", Protocol: vboxwebsrv.NATProtocolTCP, HostIP: "::1", HostPort: 8080, GuestPort: 80},
+		},
+		{
+			rule: ",1,,1,,65535",
+			want: NATRedirect{Protocol: vboxwebsrv.NATProtocolTCP, HostPort: 1, GuestPort: 65535},
+		},
+		{rule: "", wantErr: true},
+		{rule: "ssh,1,,2222,22", wantErr: true},
+		{rule: "ssh,1,,2222,,22,extra", wantErr: true},
+		{rule: "ssh,tcp,,2222,,22", wantErr: true},
+		{rule: "ssh,2,,2222,,22", wantErr: true},
+		{rule: "ssh,1,,,,22", wantErr: true},
+		{rule: "ssh,1,,65536,,22", wantErr: true},
+		{rule: "ssh,1,,2222,,-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseNATRedirect(tt.rule)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseNATRedirect(%q) = %+v, want error", tt.rule, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseNATRedirect(%q) returned error: %v", tt.rule, err)
+		} else if got != tt.want {
+			t.Errorf("parseNATRedirect(%q) = %+v, want %+v", tt.rule, got, tt.want)
+		}
+	}
+}
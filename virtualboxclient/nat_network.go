@@ -0,0 +1,400 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// NATLoopbackMapping maps a host loopback address into a NAT network.
+type NATLoopbackMapping struct {
+	HostId string
+	Offset int32
+}
+
+type NATNetwork struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+// AddLocalMapping makes the host loopback address hostId reachable from the
+// guests at the given offset within the network. An offset of 0 removes the
+// mapping.
+func (nn *NATNetwork) AddLocalMapping(hostId string, offset int32) error {
+	request := vboxwebsrv.INATNetworkaddLocalMapping{This: nn.managedObjectId, Hostid: hostId, Offset: offset}
+
+	_, err := nn.virtualbox.INATNetworkaddLocalMapping(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) AddPortForwardRule(isIpv6 bool, rule NATRedirect) error {
+	request := vboxwebsrv.INATNetworkaddPortForwardRule{
+		This:      nn.managedObjectId,
+		IsIpv6:    isIpv6,
+		RuleName:  rule.Name,
+		Proto:     &rule.Protocol,
+		HostIP:    rule.HostIP,
+		HostPort:  rule.HostPort,
+		GuestIP:   rule.GuestIP,
+		GuestPort: rule.GuestPort,
+	}
+
+	_, err := nn.virtualbox.INATNetworkaddPortForwardRule(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) GetAdvertiseDefaultIPv6RouteEnabled() (bool, error) {
+	request := vboxwebsrv.INATNetworkgetAdvertiseDefaultIPv6RouteEnabled{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetAdvertiseDefaultIPv6RouteEnabled(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (nn *NATNetwork) GetEnabled() (bool, error) {
+	request := vboxwebsrv.INATNetworkgetEnabled{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetEnabled(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetGateway returns the gateway address, which is derived from the network.
+func (nn *NATNetwork) GetGateway() (string, error) {
+	request := vboxwebsrv.INATNetworkgetGateway{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetGateway(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (nn *NATNetwork) GetIPv6Enabled() (bool, error) {
+	request := vboxwebsrv.INATNetworkgetIPv6Enabled{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetIPv6Enabled(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (nn *NATNetwork) GetIPv6Prefix() (string, error) {
+	request := vboxwebsrv.INATNetworkgetIPv6Prefix{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetIPv6Prefix(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (nn *NATNetwork) GetLocalMappings() ([]NATLoopbackMapping, error) {
+	request := vboxwebsrv.INATNetworkgetLocalMappings{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetLocalMappings(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	mappings := make([]NATLoopbackMapping, len(response.Returnval))
+	for i, mapping := range response.Returnval {
+		if mappings[i], err = parseNATLoopbackMapping(mapping); err != nil {
+			return nil, err
+		}
+	}
+
+	return mappings, nil
+}
+
+// GetLoopbackIp6 returns the offset within the IPv6 prefix that is mapped to
+// the host's ::1, or 0 if there is none.
+func (nn *NATNetwork) GetLoopbackIp6() (int32, error) {
+	request := vboxwebsrv.INATNetworkgetLoopbackIp6{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetLoopbackIp6(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (nn *NATNetwork) GetNeedDhcpServer() (bool, error) {
+	request := vboxwebsrv.INATNetworkgetNeedDhcpServer{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetNeedDhcpServer(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (nn *NATNetwork) GetNetwork() (string, error) {
+	request := vboxwebsrv.INATNetworkgetNetwork{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetNetwork(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (nn *NATNetwork) GetNetworkName() (string, error) {
+	request := vboxwebsrv.INATNetworkgetNetworkName{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetNetworkName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (nn *NATNetwork) GetPortForwardRules4() ([]NATRedirect, error) {
+	request := vboxwebsrv.INATNetworkgetPortForwardRules4{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetPortForwardRules4(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return parseNATNetworkRules(response.Returnval)
+}
+
+func (nn *NATNetwork) GetPortForwardRules6() ([]NATRedirect, error) {
+	request := vboxwebsrv.INATNetworkgetPortForwardRules6{This: nn.managedObjectId}
+
+	response, err := nn.virtualbox.INATNetworkgetPortForwardRules6(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return parseNATNetworkRules(response.Returnval)
+}
+
+func (nn *NATNetwork) RemovePortForwardRule(isIpv6 bool, name string) error {
+	request := vboxwebsrv.INATNetworkremovePortForwardRule{This: nn.managedObjectId, ISipv6: isIpv6, RuleName: name}
+
+	_, err := nn.virtualbox.INATNetworkremovePortForwardRule(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) SetAdvertiseDefaultIPv6RouteEnabled(advertiseDefaultIPv6RouteEnabled bool) error {
+	request := vboxwebsrv.INATNetworksetAdvertiseDefaultIPv6RouteEnabled{This: nn.managedObjectId, AdvertiseDefaultIPv6RouteEnabled: advertiseDefaultIPv6RouteEnabled}
+
+	_, err := nn.virtualbox.INATNetworksetAdvertiseDefaultIPv6RouteEnabled(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) SetEnabled(enabled bool) error {
+	request := vboxwebsrv.INATNetworksetEnabled{This: nn.managedObjectId, Enabled: enabled}
+
+	_, err := nn.virtualbox.INATNetworksetEnabled(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) SetIPv6Enabled(ipv6Enabled bool) error {
+	request := vboxwebsrv.INATNetworksetIPv6Enabled{This: nn.managedObjectId, IPv6Enabled: ipv6Enabled}
+
+	_, err := nn.virtualbox.INATNetworksetIPv6Enabled(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) SetIPv6Prefix(ipv6Prefix string) error {
+	request := vboxwebsrv.INATNetworksetIPv6Prefix{This: nn.managedObjectId, IPv6Prefix: ipv6Prefix}
+
+	_, err := nn.virtualbox.INATNetworksetIPv6Prefix(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) SetLoopbackIp6(loopbackIp6 int32) error {
+	request := vboxwebsrv.INATNetworksetLoopbackIp6{This: nn.managedObjectId, LoopbackIp6: loopbackIp6}
+
+	_, err := nn.virtualbox.INATNetworksetLoopbackIp6(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) SetNeedDhcpServer(needDhcpServer bool) error {
+	request := vboxwebsrv.INATNetworksetNeedDhcpServer{This: nn.managedObjectId, NeedDhcpServer: needDhcpServer}
+
+	_, err := nn.virtualbox.INATNetworksetNeedDhcpServer(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// SetNetwork sets the IPv4 network in CIDR notation, e.g. "10.0.2.0/24".
+func (nn *NATNetwork) SetNetwork(network string) error {
+	request := vboxwebsrv.INATNetworksetNetwork{This: nn.managedObjectId, Network: network}
+
+	_, err := nn.virtualbox.INATNetworksetNetwork(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) SetNetworkName(networkName string) error {
+	request := vboxwebsrv.INATNetworksetNetworkName{This: nn.managedObjectId, NetworkName: networkName}
+
+	_, err := nn.virtualbox.INATNetworksetNetworkName(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// Start starts the NAT network service. VBoxManage passes "whatever" as
+// trunkType.
+func (nn *NATNetwork) Start(trunkType string) error {
+	request := vboxwebsrv.INATNetworkstart{This: nn.managedObjectId, TrunkType: trunkType}
+
+	_, err := nn.virtualbox.INATNetworkstart(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (nn *NATNetwork) Stop() error {
+	request := vboxwebsrv.INATNetworkstop{This: nn.managedObjectId}
+
+	_, err := nn.virtualbox.INATNetworkstop(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// parseNATNetworkRules parses port-forward rules in the form
+// "name:protocol:[hostIP]:hostPort:[guestIP]:guestPort".
+func parseNATNetworkRules(rules []string) ([]NATRedirect, error) {
+	redirects := make([]NATRedirect, len(rules))
+	for i, rule := range rules {
+		fields := strings.SplitN(rule, ":", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed NAT network rule %q", rule)
+		}
+
+		redirects[i].Name = fields[0]
+
+		switch strings.ToLower(fields[1]) {
+		case "tcp":
+			redirects[i].Protocol = vboxwebsrv.NATProtocolTCP
+		case "udp":
+			redirects[i].Protocol = vboxwebsrv.NATProtocolUDP
+		default:
+			return nil, fmt.Errorf("unknown protocol in NAT network rule %q", rule)
+		}
+
+		hostIP, hostPort, rest, err := parseNATNetworkEndpoint(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("malformed NAT network rule %q: %v", rule, err)
+		}
+
+		if !strings.HasPrefix(rest, ":") {
+			return nil, fmt.Errorf("malformed NAT network rule %q", rule)
+		}
+
+		guestIP, guestPort, rest, err := parseNATNetworkEndpoint(rest[1:])
+		if err != nil || rest != "" {
+			return nil, fmt.Errorf("malformed NAT network rule %q", rule)
+		}
+
+		redirects[i].HostIP, redirects[i].HostPort = hostIP, hostPort
+		redirects[i].GuestIP, redirects[i].GuestPort = guestIP, guestPort
+	}
+
+	return redirects, nil
+}
+
+// parseNATNetworkEndpoint parses "[ip]:port" from the start of s and returns
+// the remainder.
+func parseNATNetworkEndpoint(s string) (string, uint16, string, error) {
+	end := strings.Index(s, "]:")
+	if !strings.HasPrefix(s, "[") || end < 0 {
+		return "", 0, "", fmt.Errorf("expected [address]:port")
+	}
+
+	ip, s := s[1:end], s[end+2:]
+
+	portEnd := strings.Index(s, ":")
+	if portEnd < 0 {
+		portEnd = len(s)
+	}
+
+	port, err := strconv.ParseUint(s[:portEnd], 10, 16)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	return ip, uint16(port), s[portEnd:], nil
+}
+
+// parseNATLoopbackMapping parses a mapping in the form "hostId=offset".
+func parseNATLoopbackMapping(mapping string) (NATLoopbackMapping, error) {
+	fields := strings.SplitN(mapping, "=", 2)
+	if len(fields) != 2 {
+		return NATLoopbackMapping{}, fmt.Errorf("malformed NAT loopback mapping %q", mapping)
+	}
+
+	offset, err := strconv.ParseInt(fields[1], 10, 32)
+	if err != nil {
+		return NATLoopbackMapping{}, fmt.Errorf("malformed NAT loopback mapping %q", mapping)
+	}
+
+	return NATLoopbackMapping{HostId: fields[0], Offset: int32(offset)}, nil
+}
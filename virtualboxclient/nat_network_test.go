@@ -0,0 +1,64 @@
+package virtualboxclient
+
+import (
+	"testing"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+func TestParseNATNetworkRules(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    NATRedirect
+		wantErr bool
+	}{
+		{
+			rule: "ssh:tcp:[]:2222:[10.0.2.4]:22",
+			want: NATRedirect{Name: "ssh", Protocol: vboxwebsrv.NATProtocolTCP, HostPort: 2222, GuestIP: "10.0.2.4", GuestPort: 22},
+		},
+		{
+			rule: "dns:UDP:[127.0.0.1]:5353:[10.0.2.4]:53",
+			want: NATRedirect{Name: "dns", Protocol: vboxwebsrv.NATProtocolUDP, HostIP: "127.0.0.1", HostPort: 5353, GuestIP: "10.0.2.4", GuestPort: 53},
+		},
+		{
+			rule: "web6:tcp:[::1]:8080:[fd17:625c:f037:2::4]:80",
+			want: NATRedirect{Name: "web6", Protocol: vboxwebsrv.NATProtocolTCP, HostIP: "::1", HostPort: 8080, GuestIP: "fd17:625c:f037:2::4", GuestPort: 80},
+		},
+		{
+			rule: "any6:udp:[]:53:[fd17:625c:f037:2::4]:53",
+			want: NATRedirect{Name: "any6", Protocol: vboxwebsrv.NATProtocolUDP, HostPort: 53, GuestIP: "fd17:625c:f037:2::4", GuestPort: 53},
+		},
+		{rule: "", wantErr: true},
+		{rule: "ssh:tcp", wantErr: true},
+		{rule: "ssh:sctp:[]:2222:[10.0.2.4]:22", wantErr: true},
+		{rule: "ssh:tcp:2222:[10.0.2.4]:22", wantErr: true},
+		{rule: "ssh:tcp:[]:2222", wantErr: true},
+		{rule: "ssh:tcp:[]:2222:10.0.2.4:22", wantErr: true},
+		{rule: "ssh:tcp:[]:2222:[10.0.2.4]:22:extra", wantErr: true},
+		{rule: "ssh:tcp:[]:65536:[10.0.2.4]:22", wantErr: true},
+		{rule: "ssh:tcp:[]::[10.0.2.4]:22", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseNATNetworkRules([]string{tt.rule})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseNATNetworkRules(%q) = %+v, want error", tt.rule, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseNATNetworkRules(%q) returned error: %v", tt.rule, err)
+		} else if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("parseNATNetworkRules(%q) = %+v, want [%+v]", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestParseNATNetworkRulesEmpty(t *testing.T) {
+	got, err := parseNATNetworkRules(nil)
+	if err != nil || len(got) != 0 {
+		t.Errorf("parseNATNetworkRules(nil) = %+v, %v, want no rules", got, err)
+	}
+}
@@ -1,6 +1,8 @@
 package virtualboxclient
 
 import (
+	"strings"
+
 	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
 )
 
@@ -9,6 +11,132 @@ type NetworkAdapter struct {
 	managedObjectId string
 }
 
+func (na *NetworkAdapter) GetAdapterType() (*vboxwebsrv.NetworkAdapterType, error) {
+	request := vboxwebsrv.INetworkAdaptergetAdapterType{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetAdapterType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetAttachmentType() (*vboxwebsrv.NetworkAttachmentType, error) {
+	request := vboxwebsrv.INetworkAdaptergetAttachmentType{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetAttachmentType(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetBandwidthGroup returns nil if the adapter is not in a bandwidth group.
+func (na *NetworkAdapter) GetBandwidthGroup() (*BandwidthGroup, error) {
+	request := vboxwebsrv.INetworkAdaptergetBandwidthGroup{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetBandwidthGroup(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	if response.Returnval == "" {
+		return nil, nil
+	}
+
+	return &BandwidthGroup{na.virtualbox, response.Returnval}, nil
+}
+
+func (na *NetworkAdapter) GetBootPriority() (uint32, error) {
+	request := vboxwebsrv.INetworkAdaptergetBootPriority{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetBootPriority(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetBridgedInterface() (string, error) {
+	request := vboxwebsrv.INetworkAdaptergetBridgedInterface{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetBridgedInterface(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetCableConnected() (bool, error) {
+	request := vboxwebsrv.INetworkAdaptergetCableConnected{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetCableConnected(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetEnabled() (bool, error) {
+	request := vboxwebsrv.INetworkAdaptergetEnabled{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetEnabled(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetGenericDriver() (string, error) {
+	request := vboxwebsrv.INetworkAdaptergetGenericDriver{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetGenericDriver(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetHostOnlyInterface() (string, error) {
+	request := vboxwebsrv.INetworkAdaptergetHostOnlyInterface{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetHostOnlyInterface(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetInternalNetwork() (string, error) {
+	request := vboxwebsrv.INetworkAdaptergetInternalNetwork{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetInternalNetwork(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetLineSpeed() (uint32, error) {
+	request := vboxwebsrv.INetworkAdaptergetLineSpeed{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetLineSpeed(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
 func (na *NetworkAdapter) GetMACAddress() (string, error) {
 	request := vboxwebsrv.INetworkAdaptergetMACAddress{This: na.managedObjectId}
 
@@ -19,3 +147,293 @@ func (na *NetworkAdapter) GetMACAddress() (string, error) {
 
 	return response.Returnval, nil
 }
+
+func (na *NetworkAdapter) GetNATNetwork() (string, error) {
+	request := vboxwebsrv.INetworkAdaptergetNATNetwork{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetNATNetwork(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetNATEngine() (*NATEngine, error) {
+	request := vboxwebsrv.INetworkAdaptergetNATEngine{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetNATEngine(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &NATEngine{na.virtualbox, response.Returnval}, nil
+}
+
+func (na *NetworkAdapter) GetPromiscModePolicy() (*vboxwebsrv.NetworkAdapterPromiscModePolicy, error) {
+	request := vboxwebsrv.INetworkAdaptergetPromiscModePolicy{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetPromiscModePolicy(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// GetProperties returns the named generic driver properties, or all of them if
+// no names are given.
+func (na *NetworkAdapter) GetProperties(names ...string) (map[string]string, error) {
+	request := vboxwebsrv.INetworkAdaptergetProperties{This: na.managedObjectId, Names: strings.Join(names, ",")}
+
+	response, err := na.virtualbox.INetworkAdaptergetProperties(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	properties := make(map[string]string, len(response.ReturnNames))
+	for i, name := range response.ReturnNames {
+		if i < len(response.Returnval) {
+			properties[name] = response.Returnval[i]
+		}
+	}
+
+	return properties, nil
+}
+
+func (na *NetworkAdapter) GetProperty(key string) (string, error) {
+	request := vboxwebsrv.INetworkAdaptergetProperty{This: na.managedObjectId, Key: key}
+
+	response, err := na.virtualbox.INetworkAdaptergetProperty(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetSlot() (uint32, error) {
+	request := vboxwebsrv.INetworkAdaptergetSlot{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetSlot(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetTraceEnabled() (bool, error) {
+	request := vboxwebsrv.INetworkAdaptergetTraceEnabled{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetTraceEnabled(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) GetTraceFile() (string, error) {
+	request := vboxwebsrv.INetworkAdaptergetTraceFile{This: na.managedObjectId}
+
+	response, err := na.virtualbox.INetworkAdaptergetTraceFile(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (na *NetworkAdapter) SetAdapterType(adapterType vboxwebsrv.NetworkAdapterType) error {
+	request := vboxwebsrv.INetworkAdaptersetAdapterType{This: na.managedObjectId, AdapterType: &adapterType}
+
+	_, err := na.virtualbox.INetworkAdaptersetAdapterType(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetAttachmentType(attachmentType vboxwebsrv.NetworkAttachmentType) error {
+	request := vboxwebsrv.INetworkAdaptersetAttachmentType{This: na.managedObjectId, AttachmentType: &attachmentType}
+
+	_, err := na.virtualbox.INetworkAdaptersetAttachmentType(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// SetBandwidthGroup moves the adapter into group, or out of any group if group
+// is nil.
+func (na *NetworkAdapter) SetBandwidthGroup(group *BandwidthGroup) error {
+	request := vboxwebsrv.INetworkAdaptersetBandwidthGroup{This: na.managedObjectId}
+	if group != nil {
+		request.BandwidthGroup = group.managedObjectId
+	}
+
+	_, err := na.virtualbox.INetworkAdaptersetBandwidthGroup(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetBootPriority(bootPriority uint32) error {
+	request := vboxwebsrv.INetworkAdaptersetBootPriority{This: na.managedObjectId, BootPriority: bootPriority}
+
+	_, err := na.virtualbox.INetworkAdaptersetBootPriority(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetBridgedInterface(bridgedInterface string) error {
+	request := vboxwebsrv.INetworkAdaptersetBridgedInterface{This: na.managedObjectId, BridgedInterface: bridgedInterface}
+
+	_, err := na.virtualbox.INetworkAdaptersetBridgedInterface(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetCableConnected(cableConnected bool) error {
+	request := vboxwebsrv.INetworkAdaptersetCableConnected{This: na.managedObjectId, CableConnected: cableConnected}
+
+	_, err := na.virtualbox.INetworkAdaptersetCableConnected(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetEnabled(enabled bool) error {
+	request := vboxwebsrv.INetworkAdaptersetEnabled{This: na.managedObjectId, Enabled: enabled}
+
+	_, err := na.virtualbox.INetworkAdaptersetEnabled(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetGenericDriver(genericDriver string) error {
+	request := vboxwebsrv.INetworkAdaptersetGenericDriver{This: na.managedObjectId, GenericDriver: genericDriver}
+
+	_, err := na.virtualbox.INetworkAdaptersetGenericDriver(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetHostOnlyInterface(hostOnlyInterface string) error {
+	request := vboxwebsrv.INetworkAdaptersetHostOnlyInterface{This: na.managedObjectId, HostOnlyInterface: hostOnlyInterface}
+
+	_, err := na.virtualbox.INetworkAdaptersetHostOnlyInterface(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetInternalNetwork(internalNetwork string) error {
+	request := vboxwebsrv.INetworkAdaptersetInternalNetwork{This: na.managedObjectId, InternalNetwork: internalNetwork}
+
+	_, err := na.virtualbox.INetworkAdaptersetInternalNetwork(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetLineSpeed(lineSpeed uint32) error {
+	request := vboxwebsrv.INetworkAdaptersetLineSpeed{This: na.managedObjectId, LineSpeed: lineSpeed}
+
+	_, err := na.virtualbox.INetworkAdaptersetLineSpeed(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// SetMACAddress generates a new random address if macAddress is empty.
+func (na *NetworkAdapter) SetMACAddress(macAddress string) error {
+	request := vboxwebsrv.INetworkAdaptersetMACAddress{This: na.managedObjectId, MACAddress: macAddress}
+
+	_, err := na.virtualbox.INetworkAdaptersetMACAddress(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetNATNetwork(natNetwork string) error {
+	request := vboxwebsrv.INetworkAdaptersetNATNetwork{This: na.managedObjectId, NATNetwork: natNetwork}
+
+	_, err := na.virtualbox.INetworkAdaptersetNATNetwork(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetPromiscModePolicy(promiscModePolicy vboxwebsrv.NetworkAdapterPromiscModePolicy) error {
+	request := vboxwebsrv.INetworkAdaptersetPromiscModePolicy{This: na.managedObjectId, PromiscModePolicy: &promiscModePolicy}
+
+	_, err := na.virtualbox.INetworkAdaptersetPromiscModePolicy(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetProperty(key, value string) error {
+	request := vboxwebsrv.INetworkAdaptersetProperty{This: na.managedObjectId, Key: key, Value: value}
+
+	_, err := na.virtualbox.INetworkAdaptersetProperty(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetTraceEnabled(traceEnabled bool) error {
+	request := vboxwebsrv.INetworkAdaptersetTraceEnabled{This: na.managedObjectId, TraceEnabled: traceEnabled}
+
+	_, err := na.virtualbox.INetworkAdaptersetTraceEnabled(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) SetTraceFile(traceFile string) error {
+	request := vboxwebsrv.INetworkAdaptersetTraceFile{This: na.managedObjectId, TraceFile: traceFile}
+
+	_, err := na.virtualbox.INetworkAdaptersetTraceFile(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
@@ -0,0 +1,288 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// NetworkAdapterConfig is a snapshot of a network adapter's settings. Use
+// NetworkAdapter.GetConfig to read it and NetworkAdapter.ApplyConfig, on an
+// adapter of the mutable machine inside Machine.WithLock, to write it back.
+type NetworkAdapterConfig struct {
+	AdapterType vboxwebsrv.NetworkAdapterType
+	Enabled     bool
+
+	// An empty MACAddress makes ApplyConfig generate a new random address
+	MACAddress string
+
+	AttachmentType vboxwebsrv.NetworkAttachmentType
+
+	// Name of the bridged or host-only interface, internal network, NAT
+	// network or generic driver, depending on AttachmentType
+	AttachmentName string
+
+	CableConnected    bool
+	LineSpeed         uint32
+	PromiscModePolicy vboxwebsrv.NetworkAdapterPromiscModePolicy
+	TraceEnabled      bool
+	TraceFile         string
+	BootPriority      uint32
+
+	// nil if the adapter is not in a bandwidth group
+	BandwidthGroup *BandwidthGroup
+
+	// Generic driver properties, only used with NetworkAttachmentTypeGeneric
+	GenericProperties map[string]string
+}
+
+func (na *NetworkAdapter) getAttachmentName(attachmentType vboxwebsrv.NetworkAttachmentType) (string, error) {
+	switch attachmentType {
+	case vboxwebsrv.NetworkAttachmentTypeBridged:
+		return na.GetBridgedInterface()
+	case vboxwebsrv.NetworkAttachmentTypeHostOnly:
+		return na.GetHostOnlyInterface()
+	case vboxwebsrv.NetworkAttachmentTypeInternal:
+		return na.GetInternalNetwork()
+	case vboxwebsrv.NetworkAttachmentTypeNATNetwork:
+		return na.GetNATNetwork()
+	case vboxwebsrv.NetworkAttachmentTypeGeneric:
+		return na.GetGenericDriver()
+	}
+
+	return "", nil
+}
+
+func (na *NetworkAdapter) setAttachmentName(attachmentType vboxwebsrv.NetworkAttachmentType, name string) error {
+	switch attachmentType {
+	case vboxwebsrv.NetworkAttachmentTypeBridged:
+		return na.SetBridgedInterface(name)
+	case vboxwebsrv.NetworkAttachmentTypeHostOnly:
+		return na.SetHostOnlyInterface(name)
+	case vboxwebsrv.NetworkAttachmentTypeInternal:
+		return na.SetInternalNetwork(name)
+	case vboxwebsrv.NetworkAttachmentTypeNATNetwork:
+		return na.SetNATNetwork(name)
+	case vboxwebsrv.NetworkAttachmentTypeGeneric:
+		return na.SetGenericDriver(name)
+	}
+
+	if name != "" {
+		return fmt.Errorf("attachment type %s does not take a name", attachmentType)
+	}
+
+	return nil
+}
+
+func (na *NetworkAdapter) GetConfig() (*NetworkAdapterConfig, error) {
+	var config NetworkAdapterConfig
+
+	adapterType, err := na.GetAdapterType()
+	if err != nil {
+		return nil, err
+	}
+	if adapterType != nil {
+		config.AdapterType = *adapterType
+	}
+
+	if config.Enabled, err = na.GetEnabled(); err != nil {
+		return nil, err
+	}
+
+	if config.MACAddress, err = na.GetMACAddress(); err != nil {
+		return nil, err
+	}
+
+	attachmentType, err := na.GetAttachmentType()
+	if err != nil {
+		return nil, err
+	}
+	if attachmentType != nil {
+		config.AttachmentType = *attachmentType
+	}
+
+	if config.AttachmentName, err = na.getAttachmentName(config.AttachmentType); err != nil {
+		return nil, err
+	}
+
+	if config.CableConnected, err = na.GetCableConnected(); err != nil {
+		return nil, err
+	}
+
+	if config.LineSpeed, err = na.GetLineSpeed(); err != nil {
+		return nil, err
+	}
+
+	promiscModePolicy, err := na.GetPromiscModePolicy()
+	if err != nil {
+		return nil, err
+	}
+	if promiscModePolicy != nil {
+		config.PromiscModePolicy = *promiscModePolicy
+	}
+
+	if config.TraceEnabled, err = na.GetTraceEnabled(); err != nil {
+		return nil, err
+	}
+
+	if config.TraceFile, err = na.GetTraceFile(); err != nil {
+		return nil, err
+	}
+
+	if config.BootPriority, err = na.GetBootPriority(); err != nil {
+		return nil, err
+	}
+
+	if config.BandwidthGroup, err = na.GetBandwidthGroup(); err != nil {
+		return nil, err
+	}
+
+	if config.AttachmentType == vboxwebsrv.NetworkAttachmentTypeGeneric {
+		if config.GenericProperties, err = na.GetProperties(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &config, nil
+}
+
+// ApplyConfig writes the settings in config that differ from the adapter's
+// current ones. Generic properties missing from config are removed. The
+// adapter must belong to the mutable machine inside Machine.WithLock; the
+// changes take effect once the machine's settings are saved. A running
+// machine, locked with LockTypeShared, only accepts changes to the settings
+// VirtualBox can change at runtime, such as the attachment and the cable.
+func (na *NetworkAdapter) ApplyConfig(config *NetworkAdapterConfig) error {
+	current, err := na.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if config.AdapterType != "" && config.AdapterType != current.AdapterType {
+		if err := na.SetAdapterType(config.AdapterType); err != nil {
+			return err
+		}
+	}
+
+	if config.Enabled != current.Enabled {
+		if err := na.SetEnabled(config.Enabled); err != nil {
+			return err
+		}
+	}
+
+	// An empty address asks for a new one, so it always differs
+	if config.MACAddress == "" || !strings.EqualFold(config.MACAddress, current.MACAddress) {
+		if err := na.SetMACAddress(config.MACAddress); err != nil {
+			return err
+		}
+	}
+
+	// The attachment name has to be set before switching the attachment type
+	if config.AttachmentType != current.AttachmentType || config.AttachmentName != current.AttachmentName {
+		if err := na.setAttachmentName(config.AttachmentType, config.AttachmentName); err != nil {
+			return err
+		}
+	}
+
+	if config.AttachmentType != "" && config.AttachmentType != current.AttachmentType {
+		if err := na.SetAttachmentType(config.AttachmentType); err != nil {
+			return err
+		}
+	}
+
+	if config.CableConnected != current.CableConnected {
+		if err := na.SetCableConnected(config.CableConnected); err != nil {
+			return err
+		}
+	}
+
+	if config.LineSpeed != current.LineSpeed {
+		if err := na.SetLineSpeed(config.LineSpeed); err != nil {
+			return err
+		}
+	}
+
+	if config.PromiscModePolicy != "" && config.PromiscModePolicy != current.PromiscModePolicy {
+		if err := na.SetPromiscModePolicy(config.PromiscModePolicy); err != nil {
+			return err
+		}
+	}
+
+	if config.TraceFile != current.TraceFile {
+		if err := na.SetTraceFile(config.TraceFile); err != nil {
+			return err
+		}
+	}
+
+	if config.TraceEnabled != current.TraceEnabled {
+		if err := na.SetTraceEnabled(config.TraceEnabled); err != nil {
+			return err
+		}
+	}
+
+	if config.BootPriority != current.BootPriority {
+		if err := na.SetBootPriority(config.BootPriority); err != nil {
+			return err
+		}
+	}
+
+	sameGroup, err := sameBandwidthGroup(config.BandwidthGroup, current.BandwidthGroup)
+	if err != nil {
+		return err
+	}
+	if !sameGroup {
+		if err := na.SetBandwidthGroup(config.BandwidthGroup); err != nil {
+			return err
+		}
+	}
+
+	if config.AttachmentType == vboxwebsrv.NetworkAttachmentTypeGeneric {
+		properties, err := na.GetProperties()
+		if err != nil {
+			return err
+		}
+
+		for key := range properties {
+			if _, ok := config.GenericProperties[key]; ok {
+				continue
+			}
+
+			// Setting an empty value removes the property
+			if err := na.SetProperty(key, ""); err != nil {
+				return err
+			}
+		}
+
+		for key, value := range config.GenericProperties {
+			if old, ok := properties[key]; ok && old == value {
+				continue
+			}
+
+			if err := na.SetProperty(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameBandwidthGroup compares two groups, either of which may be nil, by name.
+func sameBandwidthGroup(a, b *BandwidthGroup) (bool, error) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, nil
+	}
+
+	aName, err := a.GetName()
+	if err != nil {
+		return false, err
+	}
+
+	bName, err := b.GetName()
+	if err != nil {
+		return false, err
+	}
+
+	return aName == bName, nil
+}
@@ -0,0 +1,256 @@
+package virtualboxclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type NetworkNodeKind string
+
+const (
+	NetworkNodeKindMachine    NetworkNodeKind = "machine"
+	NetworkNodeKindNAT        NetworkNodeKind = "nat"
+	NetworkNodeKindNATNetwork NetworkNodeKind = "natnetwork"
+	NetworkNodeKindInternal   NetworkNodeKind = "internal"
+	NetworkNodeKindHostOnly   NetworkNodeKind = "hostonly"
+	NetworkNodeKindBridged    NetworkNodeKind = "bridged"
+	NetworkNodeKindGeneric    NetworkNodeKind = "generic"
+)
+
+type NetworkNode struct {
+	Id   string          `json:"id"`
+	Kind NetworkNodeKind `json:"kind"`
+	Name string          `json:"name"`
+}
+
+// NetworkEdge is an enabled network adapter connecting a machine to a network.
+type NetworkEdge struct {
+	From           string                           `json:"from"`
+	To             string                           `json:"to"`
+	Slot           uint32                           `json:"slot"`
+	MACAddress     string                           `json:"macAddress"`
+	AttachmentType vboxwebsrv.NetworkAttachmentType `json:"attachmentType"`
+}
+
+type NetworkTopology struct {
+	Nodes []*NetworkNode `json:"nodes"`
+	Edges []*NetworkEdge `json:"edges"`
+
+	nodes map[string]*NetworkNode
+}
+
+// addNode returns the node of the given kind identified by key, creating it if
+// necessary.
+func (t *NetworkTopology) addNode(kind NetworkNodeKind, key, name string) *NetworkNode {
+	id := string(kind) + ":" + key
+	if node, ok := t.nodes[id]; ok {
+		return node
+	}
+
+	node := &NetworkNode{Id: id, Kind: kind, Name: name}
+	t.nodes[id] = node
+	t.Nodes = append(t.Nodes, node)
+
+	return node
+}
+
+var networkNodeKinds = map[vboxwebsrv.NetworkAttachmentType]NetworkNodeKind{
+	vboxwebsrv.NetworkAttachmentTypeNAT:        NetworkNodeKindNAT,
+	vboxwebsrv.NetworkAttachmentTypeNATNetwork: NetworkNodeKindNATNetwork,
+	vboxwebsrv.NetworkAttachmentTypeInternal:   NetworkNodeKindInternal,
+	vboxwebsrv.NetworkAttachmentTypeHostOnly:   NetworkNodeKindHostOnly,
+	vboxwebsrv.NetworkAttachmentTypeBridged:    NetworkNodeKindBridged,
+	vboxwebsrv.NetworkAttachmentTypeGeneric:    NetworkNodeKindGeneric,
+}
+
+// GetNetworkTopology returns a graph of every accessible machine's enabled
+// network adapters and the networks they are attached to. NAT networks and
+// host interfaces appear even if no machine uses them. Every machine with a
+// NAT adapter gets its own NAT node, since NAT engines are private to the
+// machine. Machines are identified by ID, networks by name.
+func (vb *VirtualBox) GetNetworkTopology() (*NetworkTopology, error) {
+	t := &NetworkTopology{nodes: make(map[string]*NetworkNode)}
+
+	machines, err := vb.GetMachines()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, machine := range machines {
+		if err := t.addMachine(machine); err != nil {
+			return nil, err
+		}
+	}
+
+	networks, err := vb.GetNATNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, network := range networks {
+		name, err := network.GetNetworkName()
+		if err != nil {
+			return nil, err
+		}
+
+		t.addNode(NetworkNodeKindNATNetwork, name, name)
+	}
+
+	host, err := vb.GetHost()
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces, err := host.GetNetworkInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hni := range interfaces {
+		name, err := hni.GetName()
+		if err != nil {
+			return nil, err
+		}
+
+		interfaceType, err := hni.GetInterfaceType()
+		if err != nil {
+			return nil, err
+		}
+
+		if interfaceType != nil && *interfaceType == vboxwebsrv.HostNetworkInterfaceTypeHostOnly {
+			t.addNode(NetworkNodeKindHostOnly, name, name)
+		} else {
+			t.addNode(NetworkNodeKindBridged, name, name)
+		}
+	}
+
+	return t, nil
+}
+
+func (t *NetworkTopology) addMachine(machine *Machine) error {
+	accessible, err := machine.GetAccessible()
+	if err != nil {
+		return err
+	}
+	if !accessible {
+		return nil
+	}
+
+	name, err := machine.GetName()
+	if err != nil {
+		return err
+	}
+
+	id, err := machine.GetId()
+	if err != nil {
+		return err
+	}
+
+	machineNode := t.addNode(NetworkNodeKindMachine, id, name)
+
+	adapters, err := machine.GetNetworkAdapters()
+	if err != nil {
+		return err
+	}
+
+	for slot, adapter := range adapters {
+		enabled, err := adapter.GetEnabled()
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			continue
+		}
+
+		attachmentType, err := adapter.GetAttachmentType()
+		if err != nil {
+			return err
+		}
+
+		if attachmentType == nil {
+			continue
+		}
+
+		kind, ok := networkNodeKinds[*attachmentType]
+		if !ok {
+			// Not attached to anything
+			continue
+		}
+
+		var networkNode *NetworkNode
+		if kind == NetworkNodeKindNAT {
+			networkNode = t.addNode(kind, id, "NAT of "+name)
+		} else {
+			networkName, err := adapter.getAttachmentName(*attachmentType)
+			if err != nil {
+				return err
+			}
+
+			networkNode = t.addNode(kind, networkName, networkName)
+		}
+
+		mac, err := adapter.GetMACAddress()
+		if err != nil {
+			return err
+		}
+
+		t.Edges = append(t.Edges, &NetworkEdge{
+			From:           machineNode.Id,
+			To:             networkNode.Id,
+			Slot:           uint32(slot),
+			MACAddress:     mac,
+			AttachmentType: *attachmentType,
+		})
+	}
+
+	return nil
+}
+
+func (t *NetworkTopology) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(t)
+}
+
+// WriteDOT writes the topology as an undirected Graphviz graph, with machines
+// drawn as boxes and networks as ellipses.
+func (t *NetworkTopology) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("graph network {\n")
+	for _, node := range t.Nodes {
+		shape := "ellipse"
+		if node.Kind == NetworkNodeKindMachine {
+			shape = "box"
+		}
+
+		label := node.Name
+		if node.Kind != NetworkNodeKindMachine {
+			label = fmt.Sprintf("%s\n(%s)", node.Name, node.Kind)
+		}
+
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s];\n", dotQuote(node.Id), dotQuote(label), shape)
+	}
+
+	for _, edge := range t.Edges {
+		label := fmt.Sprintf("%d: %s\n%s", edge.Slot, edge.MACAddress, edge.AttachmentType)
+		fmt.Fprintf(&b, "  %s -- %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(label))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+
+	return `"` + s + `"`
+}
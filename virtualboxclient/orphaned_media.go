@@ -0,0 +1,192 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type OrphanReason string
+
+const (
+	// The medium is a base image that neither it nor any of its differencing
+	// images is attached to a machine.
+	OrphanReasonUnattached OrphanReason = "unattached"
+
+	// The medium is a differencing image that neither it nor any of its
+	// descendants is attached to a machine.
+	OrphanReasonStaleDifferencing OrphanReason = "stale differencing image"
+
+	// The medium's storage could not be accessed.
+	OrphanReasonInaccessible OrphanReason = "inaccessible"
+)
+
+type OrphanedMedium struct {
+	Medium     *Medium
+	DeviceType vboxwebsrv.DeviceType
+	Location   string
+	Reasons    []OrphanReason
+
+	// InUse is set for media that are orphaned only because they are
+	// inaccessible but are still attached to a machine. They are reported
+	// but never removed.
+	InUse bool
+}
+
+func (om *OrphanedMedium) hasReason(reason OrphanReason) bool {
+	for _, r := range om.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Remove unregisters the medium. Accessible hard disks also have their storage
+// deleted; DVD and floppy images and inaccessible media are only closed.
+func (om *OrphanedMedium) Remove(timeout int32) error {
+	if om.InUse {
+		return fmt.Errorf("medium %s is still in use", om.Location)
+	}
+
+	if om.DeviceType != vboxwebsrv.DeviceTypeHardDisk || om.hasReason(OrphanReasonInaccessible) {
+		return om.Medium.Close()
+	}
+
+	progress, err := om.Medium.DeleteStorage()
+	if err != nil {
+		return err
+	}
+
+	return progress.WaitForCompletion(timeout)
+}
+
+// FindOrphanedMedia returns the registered hard disks, DVD and floppy images
+// that are inaccessible or not used by any machine. Differencing images are
+// listed before their parents so the result can be removed in order.
+func (vb *VirtualBox) FindOrphanedMedia() ([]*OrphanedMedium, error) {
+	var orphans []*OrphanedMedium
+
+	for _, list := range []func() ([]*Medium, error){vb.GetHardDisks, vb.GetDVDImages, vb.GetFloppyImages} {
+		media, err := list()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, medium := range media {
+			tree, err := medium.GetTree()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := findOrphans(tree, true, &orphans); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return orphans, nil
+}
+
+// findOrphans appends the orphans in the tree to orphans, children first, and
+// reports whether any medium in the tree is attached to a machine.
+func findOrphans(tree *MediumTree, isBase bool, orphans *[]*OrphanedMedium) (bool, error) {
+	inUse := false
+	for _, child := range tree.Children {
+		childInUse, err := findOrphans(child, false, orphans)
+		if err != nil {
+			return false, err
+		}
+		inUse = inUse || childInUse
+	}
+
+	machineIds, err := tree.Medium.GetMachineIds()
+	if err != nil {
+		return false, err
+	}
+	inUse = inUse || len(machineIds) > 0
+
+	state, err := tree.Medium.RefreshState()
+	if err != nil {
+		return false, err
+	}
+
+	var reasons []OrphanReason
+	if !inUse && isBase {
+		reasons = append(reasons, OrphanReasonUnattached)
+	} else if !inUse {
+		reasons = append(reasons, OrphanReasonStaleDifferencing)
+	}
+	if state != nil && *state == vboxwebsrv.MediumStateInaccessible {
+		reasons = append(reasons, OrphanReasonInaccessible)
+	}
+
+	if len(reasons) == 0 {
+		return inUse, nil
+	}
+
+	deviceType, err := tree.Medium.GetDeviceType()
+	if err != nil {
+		return false, err
+	}
+
+	location, err := tree.Medium.GetLocation()
+	if err != nil {
+		return false, err
+	}
+
+	*orphans = append(*orphans, &OrphanedMedium{
+		Medium:     tree.Medium,
+		DeviceType: *deviceType,
+		Location:   location,
+		Reasons:    reasons,
+		InUse:      inUse,
+	})
+
+	return inUse, nil
+}
+
+// CollectOrphanedMedia writes a line to w for every orphaned medium and, unless
+// dryRun is set, removes those that are not in use. Each removal waits up to
+// timeout milliseconds (-1 for no limit). It returns all the orphaned media it
+// found and those of them it removed, even when it stops early because
+// writing to w or a removal failed.
+func (vb *VirtualBox) CollectOrphanedMedia(w io.Writer, dryRun bool, timeout int32) (found, removed []*OrphanedMedium, err error) {
+	found, err = vb.FindOrphanedMedia()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, om := range found {
+		reasons := make([]string, len(om.Reasons))
+		for i, reason := range om.Reasons {
+			reasons[i] = string(reason)
+		}
+
+		action := "would remove"
+		switch {
+		case om.InUse:
+			action = "skipping (in use)"
+		case !dryRun:
+			action = "removing"
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %s %s: %s\n", action, om.DeviceType, om.Location, strings.Join(reasons, ", ")); err != nil {
+			return found, removed, err
+		}
+
+		if dryRun || om.InUse {
+			continue
+		}
+
+		if err := om.Remove(timeout); err != nil {
+			return found, removed, fmt.Errorf("removing %s: %w", om.Location, err)
+		}
+		removed = append(removed, om)
+	}
+
+	return found, removed, nil
+}
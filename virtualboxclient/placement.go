@@ -0,0 +1,277 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// MachineSpec describes the resources a new machine needs.
+type MachineSpec struct {
+	// In megabytes
+	MemorySize uint32
+	CPUCount   uint32
+
+	// Labels a host must carry, with the same values, to be eligible. Only
+	// enforced by AffinityStrategy.
+	Labels map[string]string
+}
+
+// HostEndpoint is one vboxwebsrv host in a HostPool.
+type HostEndpoint struct {
+	Name       string
+	VirtualBox *VirtualBox
+	Labels     map[string]string
+}
+
+// HostCapacity is a snapshot of an endpoint's resources and of what its
+// running machines use. Memory sizes are in megabytes.
+type HostCapacity struct {
+	Endpoint *HostEndpoint
+
+	MemorySize           uint32
+	MemoryAvailable      uint32
+	ProcessorCount       uint32
+	ProcessorOnlineCount uint32
+
+	RunningMachines int
+	AllocatedMemory uint32
+	AllocatedCPUs   uint32
+
+	// Set if the endpoint could not be queried; the other fields are then zero
+	Err error
+}
+
+// Fits reports whether the host has enough free memory and online processors
+// for spec.
+func (c *HostCapacity) Fits(spec MachineSpec) bool {
+	if c.Err != nil || c.MemorySize == 0 || c.ProcessorOnlineCount == 0 {
+		return false
+	}
+
+	return spec.MemorySize <= c.MemoryAvailable && spec.CPUCount <= c.ProcessorOnlineCount
+}
+
+// PlacementStrategy chooses a host for a machine among candidates that have
+// room for it. It returns nil if none is acceptable.
+type PlacementStrategy interface {
+	Choose(spec MachineSpec, candidates []*HostCapacity) *HostCapacity
+}
+
+// LeastLoadedStrategy picks the host with the largest share of its memory left
+// free after placement, breaking ties by the fewest virtual CPUs per online
+// processor.
+type LeastLoadedStrategy struct{}
+
+func (LeastLoadedStrategy) Choose(spec MachineSpec, candidates []*HostCapacity) *HostCapacity {
+	var best *HostCapacity
+	var bestFree, bestCPULoad float64
+	for _, c := range candidates {
+		free := float64(c.MemoryAvailable-spec.MemorySize) / float64(c.MemorySize)
+		cpuLoad := float64(c.AllocatedCPUs+spec.CPUCount) / float64(c.ProcessorOnlineCount)
+
+		if best == nil || free > bestFree || (free == bestFree && cpuLoad < bestCPULoad) {
+			best, bestFree, bestCPULoad = c, free, cpuLoad
+		}
+	}
+
+	return best
+}
+
+// BinPackingStrategy picks the host with the least memory left free after
+// placement, keeping other hosts empty for large machines.
+type BinPackingStrategy struct{}
+
+func (BinPackingStrategy) Choose(spec MachineSpec, candidates []*HostCapacity) *HostCapacity {
+	var best *HostCapacity
+	for _, c := range candidates {
+		if best == nil || c.MemoryAvailable < best.MemoryAvailable {
+			best = c
+		}
+	}
+
+	return best
+}
+
+// AffinityStrategy restricts the candidates to hosts carrying all of the
+// spec's labels and lets Next choose among them. A nil Next means
+// LeastLoadedStrategy.
+type AffinityStrategy struct {
+	Next PlacementStrategy
+}
+
+func (s AffinityStrategy) Choose(spec MachineSpec, candidates []*HostCapacity) *HostCapacity {
+	var matching []*HostCapacity
+	for _, c := range candidates {
+		if hasLabels(c.Endpoint.Labels, spec.Labels) {
+			matching = append(matching, c)
+		}
+	}
+
+	if len(matching) == 0 {
+		return nil
+	}
+
+	next := s.Next
+	if next == nil {
+		next = LeastLoadedStrategy{}
+	}
+
+	return next.Choose(spec, matching)
+}
+
+func hasLabels(labels, required map[string]string) bool {
+	for key, value := range required {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HostPool places new machines on one of several vboxwebsrv hosts.
+type HostPool struct {
+	Endpoints []*HostEndpoint
+
+	// nil means LeastLoadedStrategy
+	Strategy PlacementStrategy
+}
+
+// machineOnlineStates are the states in which a machine holds host resources.
+var machineOnlineStates = map[vboxwebsrv.MachineState]bool{
+	vboxwebsrv.MachineStateRunning:                true,
+	vboxwebsrv.MachineStatePaused:                 true,
+	vboxwebsrv.MachineStateStuck:                  true,
+	vboxwebsrv.MachineStateTeleporting:            true,
+	vboxwebsrv.MachineStateLiveSnapshotting:       true,
+	vboxwebsrv.MachineStateStarting:               true,
+	vboxwebsrv.MachineStateStopping:               true,
+	vboxwebsrv.MachineStateSaving:                 true,
+	vboxwebsrv.MachineStateRestoring:              true,
+	vboxwebsrv.MachineStateTeleportingPausedVM:    true,
+	vboxwebsrv.MachineStateTeleportingIn:          true,
+	vboxwebsrv.MachineStateFaultTolerantSyncing:   true,
+	vboxwebsrv.MachineStateDeletingSnapshotOnline: true,
+	vboxwebsrv.MachineStateDeletingSnapshotPaused: true,
+}
+
+// GetCapacity queries the host's resources and the usage of its running
+// machines.
+func (e *HostEndpoint) GetCapacity() (*HostCapacity, error) {
+	c := &HostCapacity{Endpoint: e}
+
+	host, err := e.VirtualBox.GetHost()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []struct {
+		value *uint32
+		get   func() (uint32, error)
+	}{
+		{&c.MemorySize, host.GetMemorySize},
+		{&c.MemoryAvailable, host.GetMemoryAvailable},
+		{&c.ProcessorCount, host.GetProcessorCount},
+		{&c.ProcessorOnlineCount, host.GetProcessorOnlineCount},
+	} {
+		if *field.value, err = field.get(); err != nil {
+			return nil, err
+		}
+	}
+
+	machines, err := e.VirtualBox.GetMachines()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, machine := range machines {
+		accessible, err := machine.GetAccessible()
+		if err != nil {
+			return nil, err
+		}
+		if !accessible {
+			continue
+		}
+
+		state, err := machine.GetState()
+		if err != nil {
+			return nil, err
+		}
+		if state == nil || !machineOnlineStates[*state] {
+			continue
+		}
+
+		memorySize, err := machine.GetMemorySize()
+		if err != nil {
+			return nil, err
+		}
+
+		cpuCount, err := machine.GetCPUCount()
+		if err != nil {
+			return nil, err
+		}
+
+		c.RunningMachines++
+		c.AllocatedMemory += memorySize
+		c.AllocatedCPUs += cpuCount
+	}
+
+	return c, nil
+}
+
+// GetCapacities queries all endpoints concurrently. Endpoints that fail are
+// reported with Err set rather than failing the whole call.
+func (p *HostPool) GetCapacities() []*HostCapacity {
+	capacities := make([]*HostCapacity, len(p.Endpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range p.Endpoints {
+		wg.Add(1)
+		go func(i int, endpoint *HostEndpoint) {
+			defer wg.Done()
+
+			c, err := endpoint.GetCapacity()
+			if err != nil {
+				c = &HostCapacity{Endpoint: endpoint, Err: err}
+			}
+			capacities[i] = c
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	return capacities
+}
+
+// Place chooses the endpoint to create a machine with the given spec on.
+func (p *HostPool) Place(spec MachineSpec) (*HostEndpoint, error) {
+	var candidates []*HostCapacity
+	var unreachable []string
+	for _, c := range p.GetCapacities() {
+		if c.Err != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s: %v", c.Endpoint.Name, c.Err))
+			continue
+		}
+
+		if c.Fits(spec) {
+			candidates = append(candidates, c)
+		}
+	}
+
+	strategy := p.Strategy
+	if strategy == nil {
+		strategy = LeastLoadedStrategy{}
+	}
+
+	if chosen := strategy.Choose(spec, candidates); chosen != nil {
+		return chosen.Endpoint, nil
+	}
+
+	if len(unreachable) > 0 {
+		return nil, fmt.Errorf("no host can run a machine with %d MB and %d CPUs (unreachable: %s)", spec.MemorySize, spec.CPUCount, strings.Join(unreachable, "; "))
+	}
+
+	return nil, fmt.Errorf("no host can run a machine with %d MB and %d CPUs", spec.MemorySize, spec.CPUCount)
+}
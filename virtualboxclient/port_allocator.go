@@ -0,0 +1,199 @@
+package virtualboxclient
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+// PortAllocator picks host ports for NAT port forwarding that no machine on
+// the server is using yet.
+type PortAllocator struct {
+	VirtualBox *VirtualBox
+
+	// Inclusive range of host ports to allocate from
+	MinPort uint16
+	MaxPort uint16
+
+	// How often to try another port when a concurrent allocation claimed the
+	// same one. Zero means 5 and a negative value means no retries.
+	Retries int
+
+	mu sync.Mutex
+}
+
+type natPort struct {
+	protocol vboxwebsrv.NATProtocol
+	port     uint16
+}
+
+// usedHostPorts counts how many machine redirects and NAT network rules on the
+// server use each host port.
+func (pa *PortAllocator) usedHostPorts() (map[natPort]int, error) {
+	machines, err := pa.VirtualBox.GetMachines()
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[natPort]int)
+	for _, machine := range machines {
+		accessible, err := machine.GetAccessible()
+		if err != nil {
+			return nil, err
+		}
+		if !accessible {
+			continue
+		}
+
+		adapters, err := machine.GetNetworkAdapters()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, adapter := range adapters {
+			natEngine, err := adapter.GetNATEngine()
+			if err != nil {
+				return nil, err
+			}
+
+			redirects, err := natEngine.GetRedirects()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, redirect := range redirects {
+				used[natPort{redirect.Protocol, redirect.HostPort}]++
+			}
+		}
+	}
+
+	networks, err := pa.VirtualBox.GetNATNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, network := range networks {
+		for _, getRules := range []func() ([]NATRedirect, error){network.GetPortForwardRules4, network.GetPortForwardRules6} {
+			rules, err := getRules()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, rule := range rules {
+				used[natPort{rule.Protocol, rule.HostPort}]++
+			}
+		}
+	}
+
+	return used, nil
+}
+
+// pickPort returns a port in the range that is not in used, starting at a
+// random offset so that concurrent allocators are unlikely to collide.
+func (pa *PortAllocator) pickPort(protocol vboxwebsrv.NATProtocol, used map[natPort]int) (uint16, error) {
+	if pa.MinPort == 0 || pa.MaxPort < pa.MinPort {
+		return 0, fmt.Errorf("invalid port range %d-%d", pa.MinPort, pa.MaxPort)
+	}
+
+	size := int(pa.MaxPort) - int(pa.MinPort) + 1
+	offset := rand.Intn(size)
+	for i := 0; i < size; i++ {
+		port := pa.MinPort + uint16((offset+i)%size)
+		if used[natPort{protocol, port}] == 0 {
+			return port, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no free %s port in range %d-%d", protocol, pa.MinPort, pa.MaxPort)
+}
+
+// Forward adds redirect to the NAT engine of the adapter in the given slot,
+// with its host port replaced by a free one from the range, and returns the
+// chosen port. The redirect is added while holding a lock on the machine, and
+// is removed and retried on another port if another machine turns out to have
+// claimed the same port in the meantime.
+func (pa *PortAllocator) Forward(machine *Machine, slot uint32, redirect NATRedirect) (uint16, error) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	retries := pa.Retries
+	if retries == 0 {
+		retries = 5
+	} else if retries < 0 {
+		retries = 0
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		used, err := pa.usedHostPorts()
+		if err != nil {
+			return 0, err
+		}
+
+		if redirect.HostPort, err = pa.pickPort(redirect.Protocol, used); err != nil {
+			return 0, err
+		}
+
+		addErr := machine.WithLock(vboxwebsrv.LockTypeShared, func(mutable *Machine) error {
+			return addRedirect(mutable, slot, redirect)
+		})
+
+		if used, err = pa.usedHostPorts(); err != nil {
+			return 0, err
+		}
+
+		if addErr != nil {
+			// Only retry if another redirect took the port in the meantime
+			if used[natPort{redirect.Protocol, redirect.HostPort}] == 0 {
+				return 0, addErr
+			}
+
+			lastErr = addErr
+			continue
+		}
+
+		if used[natPort{redirect.Protocol, redirect.HostPort}] == 1 {
+			return redirect.HostPort, nil
+		}
+		lastErr = fmt.Errorf("host port %d was claimed concurrently", redirect.HostPort)
+
+		err = machine.WithLock(vboxwebsrv.LockTypeShared, func(mutable *Machine) error {
+			return removeRedirect(mutable, slot, redirect.Name)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return 0, fmt.Errorf("unable to allocate a host port for %s after %d attempts: %v", redirect.Name, retries+1, lastErr)
+}
+
+func addRedirect(machine *Machine, slot uint32, redirect NATRedirect) error {
+	adapter, err := machine.GetNetworkAdapter(slot)
+	if err != nil {
+		return err
+	}
+
+	natEngine, err := adapter.GetNATEngine()
+	if err != nil {
+		return err
+	}
+
+	return natEngine.AddRedirect(redirect)
+}
+
+func removeRedirect(machine *Machine, slot uint32, name string) error {
+	adapter, err := machine.GetNetworkAdapter(slot)
+	if err != nil {
+		return err
+	}
+
+	natEngine, err := adapter.GetNATEngine()
+	if err != nil {
+		return err
+	}
+
+	return natEngine.RemoveRedirect(name)
+}
@@ -1,7 +1,180 @@
 package virtualboxclient
 
+import (
+	"context"
+	"fmt"
+
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
 type Progress struct {
 	virtualbox *VirtualBox
 
 	managedObjectId string
 }
+
+func (p *Progress) Cancel() error {
+	request := vboxwebsrv.IProgresscancel{This: p.managedObjectId}
+
+	_, err := p.virtualbox.IProgresscancel(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (p *Progress) GetCompleted() (bool, error) {
+	request := vboxwebsrv.IProgressgetCompleted{This: p.managedObjectId}
+
+	response, err := p.virtualbox.IProgressgetCompleted(&request)
+	if err != nil {
+		return false, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (p *Progress) GetErrorInfo() (*VirtualBoxErrorInfo, error) {
+	request := vboxwebsrv.IProgressgetErrorInfo{This: p.managedObjectId}
+
+	response, err := p.virtualbox.IProgressgetErrorInfo(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	if response.Returnval == "" {
+		return nil, nil
+	}
+
+	return &VirtualBoxErrorInfo{p.virtualbox, response.Returnval}, nil
+}
+
+func (p *Progress) GetOperationDescription() (string, error) {
+	request := vboxwebsrv.IProgressgetOperationDescription{This: p.managedObjectId}
+
+	response, err := p.virtualbox.IProgressgetOperationDescription(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (p *Progress) GetPercent() (uint32, error) {
+	request := vboxwebsrv.IProgressgetPercent{This: p.managedObjectId}
+
+	response, err := p.virtualbox.IProgressgetPercent(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (p *Progress) GetResultCode() (int32, error) {
+	request := vboxwebsrv.IProgressgetResultCode{This: p.managedObjectId}
+
+	response, err := p.virtualbox.IProgressgetResultCode(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+// Wait waits for the operation to finish, cancelling it if the context is done
+// first, in which case it returns ErrGuestTimeout if the deadline passed. It
+// returns an error if the operation completed with a failure result code.
+func (p *Progress) Wait(ctx context.Context) error {
+	return p.wait(ctx, nil)
+}
+
+// WaitForCompletion waits up to timeout milliseconds (-1 for no limit) for the
+// operation to finish. It returns an error if the operation is still running
+// when the timeout expires or if it completed with a failure result code.
+func (p *Progress) WaitForCompletion(timeout int32) error {
+	request := vboxwebsrv.IProgresswaitForCompletion{This: p.managedObjectId, Timeout: timeout}
+
+	_, err := p.virtualbox.IProgresswaitForCompletion(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	completed, err := p.GetCompleted()
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return fmt.Errorf("operation did not complete within %dms", timeout)
+	}
+
+	return p.checkResult()
+}
+
+// wait is Wait, calling report, if not nil, with the overall percentage done
+// and the description of the current operation while the operation runs and
+// once it has completed.
+func (p *Progress) wait(ctx context.Context, report func(percent uint32, operation string)) error {
+	for {
+		request := vboxwebsrv.IProgresswaitForCompletion{This: p.managedObjectId, Timeout: int32(waitTimeoutMS(ctx))}
+
+		_, err := p.virtualbox.IProgresswaitForCompletion(&request)
+		if err != nil {
+			return err // TODO: Wrap the error
+		}
+
+		completed, err := p.GetCompleted()
+		if err != nil {
+			return err
+		}
+
+		if report != nil {
+			percent, err := p.GetPercent()
+			if err != nil {
+				return err
+			}
+
+			operation, err := p.GetOperationDescription()
+			if err != nil {
+				return err
+			}
+
+			report(percent, operation)
+		}
+
+		if completed {
+			return p.checkResult()
+		}
+
+		if ctx.Err() != nil {
+			p.Cancel()
+			return contextError(ctx)
+		}
+	}
+}
+
+func (p *Progress) checkResult() error {
+	resultCode, err := p.GetResultCode()
+	if err != nil {
+		return err
+	}
+	if resultCode == 0 {
+		return nil
+	}
+
+	errorInfo, err := p.GetErrorInfo()
+	if err != nil {
+		return err
+	}
+	if errorInfo == nil {
+		return fmt.Errorf("operation failed with result code %#x", uint32(resultCode))
+	}
+
+	text, err := errorInfo.GetText()
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("operation failed with result code %#x: %s", uint32(resultCode), text)
+}
@@ -0,0 +1,50 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type Session struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+// GetConsole returns the console of the machine locked by the session, or nil
+// if the machine is not running.
+func (s *Session) GetConsole() (*Console, error) {
+	request := vboxwebsrv.ISessiongetConsole{This: s.managedObjectId}
+
+	response, err := s.virtualbox.ISessiongetConsole(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	if response.Returnval == "" {
+		return nil, nil
+	}
+
+	return &Console{s.virtualbox, response.Returnval}, nil
+}
+
+// GetMachine returns the mutable copy of the machine locked by the session.
+func (s *Session) GetMachine() (*Machine, error) {
+	request := vboxwebsrv.ISessiongetMachine{This: s.managedObjectId}
+
+	response, err := s.virtualbox.ISessiongetMachine(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Machine{s.virtualbox, response.Returnval}, nil
+}
+
+func (s *Session) UnlockMachine() error {
+	request := vboxwebsrv.ISessionunlockMachine{This: s.managedObjectId}
+
+	_, err := s.virtualbox.ISessionunlockMachine(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type Snapshot struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (s *Snapshot) GetId() (string, error) {
+	request := vboxwebsrv.ISnapshotgetId{This: s.managedObjectId}
+
+	response, err := s.virtualbox.ISnapshotgetId(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (s *Snapshot) GetName() (string, error) {
+	request := vboxwebsrv.ISnapshotgetName{This: s.managedObjectId}
+
+	response, err := s.virtualbox.ISnapshotgetName(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
@@ -1,6 +1,9 @@
 package virtualboxclient
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
 )
 
@@ -9,6 +12,39 @@ type SystemProperties struct {
 	managedObjectId string
 }
 
+// FindMediumFormat returns the medium format with the given ID, compared
+// case-insensitively as VirtualBox does.
+func (sp *SystemProperties) FindMediumFormat(id string) (*MediumFormat, error) {
+	formats, err := sp.GetMediumFormats()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, format := range formats {
+		formatId, err := format.GetId()
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.EqualFold(formatId, id) {
+			return format, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown medium format %q", id)
+}
+
+func (sp *SystemProperties) GetDefaultHardDiskFormat() (string, error) {
+	request := vboxwebsrv.ISystemPropertiesgetDefaultHardDiskFormat{This: sp.managedObjectId}
+
+	response, err := sp.virtualbox.ISystemPropertiesgetDefaultHardDiskFormat(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
 func (sp *SystemProperties) GetMaxNetworkAdapters(chipset *vboxwebsrv.ChipsetType) (uint32, error) {
 	request := vboxwebsrv.ISystemPropertiesgetMaxNetworkAdapters{This: sp.managedObjectId, Chipset: chipset}
 
@@ -19,3 +55,19 @@ func (sp *SystemProperties) GetMaxNetworkAdapters(chipset *vboxwebsrv.ChipsetTyp
 
 	return response.Returnval, nil
 }
+
+func (sp *SystemProperties) GetMediumFormats() ([]*MediumFormat, error) {
+	request := vboxwebsrv.ISystemPropertiesgetMediumFormats{This: sp.managedObjectId}
+
+	response, err := sp.virtualbox.ISystemPropertiesgetMediumFormats(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	formats := make([]*MediumFormat, len(response.Returnval))
+	for i, oid := range response.Returnval {
+		formats[i] = &MediumFormat{sp.virtualbox, oid}
+	}
+
+	return formats, nil
+}
@@ -22,9 +22,36 @@ func New(username, password, url string) *VirtualBox {
 	}
 }
 
+func (vb *VirtualBox) CreateDHCPServer(networkName string) (*DHCPServer, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxcreateDHCPServer{This: vb.managedObjectId, Name: networkName}
+
+	response, err := vb.IVirtualBoxcreateDHCPServer(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &DHCPServer{vb, response.Returnval}, nil
+}
+
+// CreateHardDisk returns a new hard disk object without storage. An empty
+// format selects the default hard disk format; any other format must be one of
+// those reported by SystemProperties.GetMediumFormats.
 func (vb *VirtualBox) CreateHardDisk(format, location string) (*Medium, error) {
 	vb.Logon()
 
+	if format != "" {
+		systemProperties, err := vb.GetSystemProperties()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := systemProperties.FindMediumFormat(format); err != nil {
+			return nil, err
+		}
+	}
+
 	request := vboxwebsrv.IVirtualBoxcreateHardDisk{This: vb.managedObjectId, Format: format, Location: location}
 
 	response, err := vb.IVirtualBoxcreateHardDisk(&request)
@@ -35,6 +62,158 @@ func (vb *VirtualBox) CreateHardDisk(format, location string) (*Medium, error) {
 	return &Medium{virtualbox: vb, managedObjectId: response.Returnval}, nil
 }
 
+func (vb *VirtualBox) CreateNATNetwork(networkName string) (*NATNetwork, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxcreateNATNetwork{This: vb.managedObjectId, NetworkName: networkName}
+
+	response, err := vb.IVirtualBoxcreateNATNetwork(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &NATNetwork{vb, response.Returnval}, nil
+}
+
+func (vb *VirtualBox) FindDHCPServerByNetworkName(networkName string) (*DHCPServer, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxfindDHCPServerByNetworkName{This: vb.managedObjectId, Name: networkName}
+
+	response, err := vb.IVirtualBoxfindDHCPServerByNetworkName(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &DHCPServer{vb, response.Returnval}, nil
+}
+
+func (vb *VirtualBox) FindMachine(nameOrId string) (*Machine, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxfindMachine{This: vb.managedObjectId, NameOrId: nameOrId}
+
+	response, err := vb.IVirtualBoxfindMachine(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Machine{vb, response.Returnval}, nil
+}
+
+func (vb *VirtualBox) FindNATNetworkByName(networkName string) (*NATNetwork, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxfindNATNetworkByName{This: vb.managedObjectId, NetworkName: networkName}
+
+	response, err := vb.IVirtualBoxfindNATNetworkByName(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &NATNetwork{vb, response.Returnval}, nil
+}
+
+func (vb *VirtualBox) GetDHCPServers() ([]*DHCPServer, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxgetDHCPServers{This: vb.managedObjectId}
+
+	response, err := vb.IVirtualBoxgetDHCPServers(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	servers := make([]*DHCPServer, len(response.Returnval))
+	for n, oid := range response.Returnval {
+		servers[n] = &DHCPServer{vb, oid}
+	}
+
+	return servers, nil
+}
+
+func (vb *VirtualBox) GetDVDImages() ([]*Medium, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxgetDVDImages{This: vb.managedObjectId}
+
+	response, err := vb.IVirtualBoxgetDVDImages(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	media := make([]*Medium, len(response.Returnval))
+	for n, oid := range response.Returnval {
+		media[n] = &Medium{vb, oid}
+	}
+
+	return media, nil
+}
+
+func (vb *VirtualBox) GetEventSource() (*EventSource, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxgetEventSource{This: vb.managedObjectId}
+
+	response, err := vb.IVirtualBoxgetEventSource(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &EventSource{vb, response.Returnval}, nil
+}
+
+func (vb *VirtualBox) GetFloppyImages() ([]*Medium, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxgetFloppyImages{This: vb.managedObjectId}
+
+	response, err := vb.IVirtualBoxgetFloppyImages(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	media := make([]*Medium, len(response.Returnval))
+	for n, oid := range response.Returnval {
+		media[n] = &Medium{vb, oid}
+	}
+
+	return media, nil
+}
+
+// GetHardDisks returns the registered base hard disks. Differencing images are
+// reachable through Medium.GetChildren.
+func (vb *VirtualBox) GetHardDisks() ([]*Medium, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxgetHardDisks{This: vb.managedObjectId}
+
+	response, err := vb.IVirtualBoxgetHardDisks(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	media := make([]*Medium, len(response.Returnval))
+	for n, oid := range response.Returnval {
+		media[n] = &Medium{vb, oid}
+	}
+
+	return media, nil
+}
+
+func (vb *VirtualBox) GetHost() (*Host, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxgetHost{This: vb.managedObjectId}
+
+	response, err := vb.IVirtualBoxgetHost(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Host{vb, response.Returnval}, nil
+}
+
 func (vb *VirtualBox) GetMachines() ([]*Machine, error) {
 	vb.Logon()
 
@@ -53,6 +232,40 @@ func (vb *VirtualBox) GetMachines() ([]*Machine, error) {
 	return machines, nil
 }
 
+func (vb *VirtualBox) GetNATNetworks() ([]*NATNetwork, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxgetNATNetworks{This: vb.managedObjectId}
+
+	response, err := vb.IVirtualBoxgetNATNetworks(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	networks := make([]*NATNetwork, len(response.Returnval))
+	for n, oid := range response.Returnval {
+		networks[n] = &NATNetwork{vb, oid}
+	}
+
+	return networks, nil
+}
+
+// GetSessionObject returns the session object belonging to the web session.
+// vboxwebsrv hands out one session object per logon, so it can only lock one
+// machine at a time.
+func (vb *VirtualBox) GetSessionObject() (*Session, error) {
+	vb.Logon()
+
+	request := vboxwebsrv.IWebsessionManagergetSessionObject{RefIVirtualBox: vb.managedObjectId}
+
+	response, err := vb.IWebsessionManagergetSessionObject(&request)
+	if err != nil {
+		return nil, err // TODO: Wrap the error
+	}
+
+	return &Session{vb, response.Returnval}, nil
+}
+
 func (vb *VirtualBox) GetSystemProperties() (*SystemProperties, error) {
 	vb.Logon()
 
@@ -66,6 +279,25 @@ func (vb *VirtualBox) GetSystemProperties() (*SystemProperties, error) {
 	return &SystemProperties{vb, response.Returnval}, nil
 }
 
+// Logoff ends the web session, releasing all objects obtained through it. The
+// next call needing a web session logs on again.
+func (vb *VirtualBox) Logoff() error {
+	if vb.managedObjectId == "" {
+		return nil
+	}
+
+	request := vboxwebsrv.IWebsessionManagerlogoff{RefIVirtualBox: vb.managedObjectId}
+
+	_, err := vb.IWebsessionManagerlogoff(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	vb.managedObjectId = ""
+
+	return nil
+}
+
 func (vb *VirtualBox) Logon() error {
 	if vb.managedObjectId != "" {
 		// Already logged in
@@ -86,3 +318,41 @@ func (vb *VirtualBox) Logon() error {
 
 	return nil
 }
+
+func (vb *VirtualBox) RemoveDHCPServer(server *DHCPServer) error {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxremoveDHCPServer{This: vb.managedObjectId, Server: server.managedObjectId}
+
+	_, err := vb.IVirtualBoxremoveDHCPServer(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+func (vb *VirtualBox) RemoveNATNetwork(network *NATNetwork) error {
+	vb.Logon()
+
+	request := vboxwebsrv.IVirtualBoxremoveNATNetwork{This: vb.managedObjectId, Network: network.managedObjectId}
+
+	_, err := vb.IVirtualBoxremoveNATNetwork(&request)
+	if err != nil {
+		return err // TODO: Wrap the error
+	}
+
+	return nil
+}
+
+// newWebSession returns a client with the same connection and credentials
+// that logs on to a web session of its own. vboxwebsrv hands out one session
+// object per web session, so this lets a second machine be locked.
+func (vb *VirtualBox) newWebSession() *VirtualBox {
+	return &VirtualBox{
+		VboxPortType: vb.VboxPortType,
+
+		username: vb.username,
+		password: vb.password,
+	}
+}
@@ -0,0 +1,32 @@
+package virtualboxclient
+
+import (
+	"github.com/appropriate/go-virtualboxclient/vboxwebsrv"
+)
+
+type VirtualBoxErrorInfo struct {
+	virtualbox      *VirtualBox
+	managedObjectId string
+}
+
+func (ei *VirtualBoxErrorInfo) GetResultCode() (int32, error) {
+	request := vboxwebsrv.IVirtualBoxErrorInfogetResultCode{This: ei.managedObjectId}
+
+	response, err := ei.virtualbox.IVirtualBoxErrorInfogetResultCode(&request)
+	if err != nil {
+		return 0, err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}
+
+func (ei *VirtualBoxErrorInfo) GetText() (string, error) {
+	request := vboxwebsrv.IVirtualBoxErrorInfogetText{This: ei.managedObjectId}
+
+	response, err := ei.virtualbox.IVirtualBoxErrorInfogetText(&request)
+	if err != nil {
+		return "", err // TODO: Wrap the error
+	}
+
+	return response.Returnval, nil
+}